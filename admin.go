@@ -0,0 +1,21 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+)
+
+// adminTokenHeader carries the admin token on admin-gated requests.
+const adminTokenHeader = "X-Admin-Token"
+
+// isAdmin reports whether r carries the token configured in ADMIN_TOKEN.
+// Admin access is disabled entirely when ADMIN_TOKEN is unset.
+func isAdmin(r *http.Request) bool {
+	token := os.Getenv("ADMIN_TOKEN")
+	if token == "" {
+		return false
+	}
+	given := r.Header.Get(adminTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// debugHeader turns on verbose logging for a single admin request.
+const debugHeader = "X-Debug"
+
+// requestIDHeader carries the ID debug logs are tagged with. A caller may
+// supply one; otherwise it is generated and echoed on the response.
+const requestIDHeader = "X-Request-ID"
+
+// debugLog logs the steps of one request. A nil *debugLog discards
+// everything, so callers need not check whether debugging is on.
+type debugLog struct {
+	id string
+}
+
+// newDebugLog returns a debugLog for r when it sets X-Debug and carries the
+// admin token, and nil otherwise. The header is ignored for everyone else.
+func newDebugLog(w http.ResponseWriter, r *http.Request) *debugLog {
+	if on, _ := strconv.ParseBool(r.Header.Get(debugHeader)); !on || !isAdmin(r) {
+		return nil
+	}
+	id := sanitizePromptText(r.Header.Get(requestIDHeader), 64)
+	if id == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	w.Header().Set(requestIDHeader, id)
+	return &debugLog{id: id}
+}
+
+// Printf logs a message tagged with the request ID, with secrets redacted.
+func (d *debugLog) Printf(format string, args ...any) {
+	if d == nil {
+		return
+	}
+	log.Printf("[debug %s] %s", d.id, redactSecrets(fmt.Sprintf(format, args...)))
+}
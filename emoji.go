@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	zeroWidthJoiner   = '\u200d'
+	variationSelector = '\ufe0f'
+	keycapCombiner    = '\u20e3'
+)
+
+// isEmoji reports whether r is in one of the Unicode blocks used for emoji
+// pictographs. Ordinary punctuation and letters of every script fall
+// outside these ranges.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, flags, skin tones
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // misc symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // arrows and stars such as ⭐
+		return true
+	case r >= 0x231A && r <= 0x23FF: // watch, hourglass, media controls
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tag sequences of subdivision flags
+		return true
+	}
+	return false
+}
+
+// stripEmojis removes emoji from s. Only the whitespace an emoji leaves
+// behind is tidied: a space on one side of it is dropped, as is a space
+// before the end of a line or before punctuation, as in "Reminders 🔔, Tips".
+// Emoji presentation selectors and keycap marks are always dropped, but the
+// zero width joiner only inside an emoji sequence because scripts such as
+// Devanagari rely on it.
+func stripEmojis(s string) string {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+	removed := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == variationSelector || r == keycapCombiner {
+			removed = true
+			i++
+			continue
+		}
+		if !isEmoji(r) {
+			out = append(out, r)
+			i++
+			continue
+		}
+
+		removed = true
+		j := i + 1
+		for j < len(runes) && (isEmoji(runes[j]) || runes[j] == variationSelector || runes[j] == keycapCombiner || runes[j] == zeroWidthJoiner) {
+			j++
+		}
+		afterBreak := len(out) == 0 || out[len(out)-1] == '\n' || isInlineSpace(out[len(out)-1])
+		if afterBreak {
+			for j < len(runes) && isInlineSpace(runes[j]) {
+				j++
+			}
+		}
+		if j == len(runes) || runes[j] == '\n' || runes[j] == '\r' || strings.ContainsRune(",.;:!?", runes[j]) {
+			for len(out) > 0 && isInlineSpace(out[len(out)-1]) {
+				out = out[:len(out)-1]
+			}
+		}
+		i = j
+	}
+	if !removed {
+		return s
+	}
+	return string(out)
+}
+
+// isInlineSpace reports whether r is whitespace other than a line break.
+func isInlineSpace(r rune) bool {
+	return r != '\n' && r != '\r' && unicode.IsSpace(r)
+}
+
+// stripIdeaEmojis removes emoji from every text field of idea. It runs
+// before the fields are validated, so list items left empty are dropped.
+func stripIdeaEmojis(idea *Idea) {
+	for _, f := range []*string{&idea.Name, &idea.Concept, &idea.Features, &idea.Effort, &idea.Monetization, &idea.MonetizationRationale, &idea.MarketSize} {
+		*f = stripEmojis(*f)
+	}
+	for i := range idea.FeatureDetails {
+		idea.FeatureDetails[i].Name = stripEmojis(idea.FeatureDetails[i].Name)
+		idea.FeatureDetails[i].Why = stripEmojis(idea.FeatureDetails[i].Why)
+	}
+	for i := range idea.Dependencies {
+		idea.Dependencies[i].Feature = stripEmojis(idea.Dependencies[i].Feature)
+		idea.Dependencies[i].Requires = stripEmojis(idea.Dependencies[i].Requires)
+	}
+	stripListEmojis(idea.Competitors)
+	stripListEmojis(idea.Risks)
+	stripListEmojis(idea.Accessibility)
+	stripListEmojis(idea.Skills)
+	if idea.SWOT != nil {
+		stripListEmojis(idea.SWOT.Strengths)
+		stripListEmojis(idea.SWOT.Weaknesses)
+		stripListEmojis(idea.SWOT.Opportunities)
+		stripListEmojis(idea.SWOT.Threats)
+	}
+	for i := range idea.Roadmap {
+		idea.Roadmap[i].Name = stripEmojis(idea.Roadmap[i].Name)
+		stripListEmojis(idea.Roadmap[i].Goals)
+	}
+}
+
+// stripListEmojis removes emoji from each item of list in place.
+func stripListEmojis(list []string) {
+	for i := range list {
+		list[i] = stripEmojis(list[i])
+	}
+}
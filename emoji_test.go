@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStripEmojis(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain text", "Plant Pal", "Plant Pal"},
+		{"single emoji", "Launch 🚀 fast", "Launch fast"},
+		{"zwj family", "For 👨‍👩‍👧‍👦 families", "For families"},
+		{"skin tone", "Wave 👋🏽 hello", "Wave hello"},
+		{"keycap", "Top 1️⃣ pick", "Top 1 pick"},
+		{"flag", "Made in 🇮🇳 India", "Made in India"},
+		{"subdivision flag", "Go 🏴󠁧󠁢󠁳󠁣󠁴󠁿 Scotland", "Go Scotland"},
+		{"emoji before punctuation", "Reminders 🔔, tips", "Reminders, tips"},
+		{"devanagari zwj", "क्‍ष सेवा", "क्‍ष सेवा"},
+		{"arabic", "تطبيق الصحة", "تطبيق الصحة"},
+		{"punctuation", "Fast, cheap & simple - really! (v2.0) #1 50% @home", "Fast, cheap & simple - really! (v2.0) #1 50% @home"},
+		{"only emoji", "🎉🎉", ""},
+		{"emoji between spaces", "Launch 🚀 🚀 fast 🎉 ", "Launch fast"},
+		{"spacing elsewhere kept", "🚀 Built with .NET and C# .", "Built with .NET and C# ."},
+		{"newlines kept", "Line one 🎉\nLine two ? yes", "Line one\nLine two ? yes"},
+		{"double spaces kept", "Two  spaces 🔔 here", "Two  spaces here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripEmojis(tt.in); got != tt.want {
+				t.Errorf("stripEmojis(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIdeaStripsEmojisFromEveryField(t *testing.T) {
+	strip := true
+	req := IdeaRequest{
+		StripEmojis:          &strip,
+		IncludeEffort:        true,
+		IncludeCompetitors:   true,
+		FeatureDependencies:  true,
+		IncludeSWOT:          true,
+		IncludeRisks:         true,
+		IncludeAccessibility: true,
+		IncludeSkills:        true,
+		IncludeRoadmap:       true,
+		IncludeMonetization:  true,
+		IncludeMarketSize:    true,
+	}
+	idea := Idea{
+		Name:                  "Plant 🌱 Pal",
+		Concept:               "Water 💧 reminders.",
+		Features:              "Reminders 🔔, Tips",
+		Effort:                "weekend 🎉",
+		Competitors:           []string{"Planta 🌿", "🌵"},
+		Dependencies:          []FeatureDependency{{Feature: "Tips 💡", Requires: "Reminders 🔔"}},
+		SWOT:                  &SWOT{Strengths: []string{"Cute 😍"}, Weaknesses: []string{"Niche 🤏"}, Opportunities: []string{"Gifts 🎁"}, Threats: []string{"Apps 📱"}},
+		Risks:                 []string{"Churn 📉"},
+		Accessibility:         []string{"Screen readers 🦻"},
+		Skills:                []string{"Go 🐹"},
+		Roadmap:               []RoadmapPhase{{Name: "MVP", Goals: []string{"Ship 🚀"}}},
+		Monetization:          "freemium 💰",
+		MonetizationRationale: "Low barrier 👍",
+		MarketSize:            "Rough estimate: big 📈",
+	}
+	if err := validateIdea(&idea, req); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(idea)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range string(data) {
+		if isEmoji(r) {
+			t.Fatalf("emoji %q left in %s", r, data)
+		}
+	}
+	if len(idea.Competitors) != 1 || idea.Competitors[0] != "Planta" {
+		t.Errorf("competitors = %q, want the emoji-only entry dropped", idea.Competitors)
+	}
+	if idea.Effort != "weekend" || idea.Monetization != "freemium" {
+		t.Errorf("effort = %q, monetization = %q; want enum values after stripping", idea.Effort, idea.Monetization)
+	}
+	if !strings.Contains(idea.Features, "Reminders, Tips") {
+		t.Errorf("features = %q", idea.Features)
+	}
+}
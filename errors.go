@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordedError is one entry in the recent errors buffer. Context holds
+// only request metadata that is safe to show; bodies and query values are
+// never recorded.
+type recordedError struct {
+	Code      int               `json:"code"`
+	Message   string            `json:"message"`
+	Timestamp time.Time         `json:"timestamp"`
+	Context   map[string]string `json:"context,omitempty"`
+}
+
+// errorRing keeps the most recent errors in memory, overwriting the oldest
+// once full.
+type errorRing struct {
+	mu      sync.Mutex
+	entries []recordedError
+	next    int
+	filled  int
+}
+
+func newErrorRing(size int) *errorRing {
+	return &errorRing{entries: make([]recordedError, size)}
+}
+
+// newErrorRingFromEnv sizes the buffer from ERROR_BUFFER_SIZE, default 50.
+func newErrorRingFromEnv() *errorRing {
+	size := 50
+	if v, err := strconv.Atoi(os.Getenv("ERROR_BUFFER_SIZE")); err == nil && v > 0 {
+		size = v
+	}
+	return newErrorRing(size)
+}
+
+// recentErrors is the process-wide buffer, set up in main.
+var recentErrors = newErrorRing(50)
+
+// Record adds an error for request r to the buffer.
+func (b *errorRing) Record(r *http.Request, code int, message string) {
+	entry := recordedError{
+		Code:      code,
+		Message:   redactSecrets(message),
+		Timestamp: time.Now().UTC(),
+	}
+	if r != nil {
+		entry.Context = map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.filled < len(b.entries) {
+		b.filled++
+	}
+}
+
+// Snapshot returns the buffered errors, newest first.
+func (b *errorRing) Snapshot() []recordedError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]recordedError, 0, b.filled)
+	for i := 1; i <= b.filled; i++ {
+		out = append(out, b.entries[(b.next-i+len(b.entries))%len(b.entries)])
+	}
+	return out
+}
+
+// redactSecrets masks configured secrets that might appear in upstream
+// error messages.
+func redactSecrets(s string) string {
+	for _, name := range []string{"GROQ_API_KEY", "ADMIN_TOKEN"} {
+		if secret := os.Getenv(name); secret != "" {
+			s = strings.ReplaceAll(s, secret, "[REDACTED]")
+		}
+	}
+	return s
+}
+
+// writeError records the error and writes it as a plain-text response, or
+// as problem details when the client asks for them.
+func writeError(w http.ResponseWriter, r *http.Request, message string, code int) {
+	recentErrors.Record(r, code, message)
+	writeErrorBody(w, r, message, code)
+}
+
+// writeErrorBody writes an error like writeError without recording it, for
+// endpoints whose failures should not show up in the errors buffer.
+func writeErrorBody(w http.ResponseWriter, r *http.Request, message string, code int) {
+	if wantsProblemDetails(r) {
+		writeProblem(w, r, message, code)
+		return
+	}
+	http.Error(w, message, code)
+}
+
+// adminErrorsHandler serves GET /api/admin/errors.
+func adminErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorBody(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isAdmin(r) {
+		writeErrorBody(w, r, "admin access required", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Errors []recordedError `json:"errors"`
+	}{Errors: recentErrors.Snapshot()})
+}
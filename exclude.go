@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxExcludeKeywords caps the exclude_keywords list, and
+// maxExcludeKeywordLen each keyword in it.
+const (
+	maxExcludeKeywords   = 10
+	maxExcludeKeywordLen = 40
+)
+
+// cleanExcludeKeywords sanitizes the request's excluded keywords, dropping
+// empty and repeated ones.
+func cleanExcludeKeywords(raw []string) ([]string, error) {
+	if len(raw) > maxExcludeKeywords {
+		return nil, fmt.Errorf("exclude_keywords can have at most %d entries", maxExcludeKeywords)
+	}
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, k := range raw {
+		if len([]rune(k)) > maxExcludeKeywordLen {
+			return nil, fmt.Errorf("each exclude_keywords entry must be at most %d characters", maxExcludeKeywordLen)
+		}
+		k = sanitizePromptText(k, maxExcludeKeywordLen)
+		if k == "" || seen[strings.ToLower(k)] {
+			continue
+		}
+		seen[strings.ToLower(k)] = true
+		keywords = append(keywords, k)
+	}
+	return keywords, nil
+}
+
+// mentionsExcluded reports whether any text field of idea mentions one of
+// keywords. Matching is case-insensitive on whole words, so "ai" does not
+// match "email"; a multi-word keyword must appear as a phrase, and a
+// trailing plural "s" or "es" on the idea's word is tolerated.
+func mentionsExcluded(idea Idea, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+	var fields [][]string
+	for _, text := range ideaTexts(idea) {
+		fields = append(fields, wordTokens(text))
+	}
+	for _, k := range keywords {
+		phrase := wordTokens(k)
+		if len(phrase) == 0 {
+			continue
+		}
+		for _, words := range fields {
+			if containsPhrase(words, phrase) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ideaTexts returns every human-readable text value of idea.
+func ideaTexts(idea Idea) []string {
+	texts := []string{idea.Name, idea.Concept, idea.Features, idea.MonetizationRationale, idea.MarketSize}
+	for _, f := range idea.FeatureDetails {
+		texts = append(texts, f.Name, f.Why)
+	}
+	texts = append(texts, idea.Competitors...)
+	texts = append(texts, idea.Risks...)
+	texts = append(texts, idea.Accessibility...)
+	texts = append(texts, idea.Skills...)
+	if idea.SWOT != nil {
+		texts = append(texts, idea.SWOT.Strengths...)
+		texts = append(texts, idea.SWOT.Weaknesses...)
+		texts = append(texts, idea.SWOT.Opportunities...)
+		texts = append(texts, idea.SWOT.Threats...)
+	}
+	for _, p := range idea.Roadmap {
+		texts = append(texts, p.Goals...)
+	}
+	return texts
+}
+
+// wordTokens splits text into lowercase words of letters and digits.
+func wordTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsPhrase reports whether phrase appears as consecutive words.
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, p := range phrase {
+			w := words[i+j]
+			if w != p && w != p+"s" && w != p+"es" {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestMentionsExcluded(t *testing.T) {
+	tests := []struct {
+		name     string
+		idea     Idea
+		keywords []string
+		want     bool
+	}{
+		{"whole word", Idea{Concept: "An AI tutor"}, []string{"ai"}, true},
+		{"inside a word", Idea{Concept: "Email digests with detail"}, []string{"ai"}, false},
+		{"art in smart", Idea{Name: "Smart Garden"}, []string{"art"}, false},
+		{"case insensitive", Idea{Features: "Blockchain ledger"}, []string{"blockchain"}, true},
+		{"plural", Idea{Concept: "Trade NFTs"}, []string{"NFT"}, true},
+		{"phrase", Idea{Concept: "Uses machine learning models"}, []string{"machine learning"}, true},
+		{"split phrase", Idea{Concept: "A machine for learning"}, []string{"machine learning"}, false},
+		{"competitors", Idea{Competitors: []string{"Coinbase crypto"}}, []string{"crypto"}, true},
+		{"skills", Idea{Skills: []string{"Solidity", "Crypto"}}, []string{"crypto"}, true},
+		{"swot", Idea{SWOT: &SWOT{Threats: []string{"Crypto regulation"}}}, []string{"crypto"}, true},
+		{"roadmap", Idea{Roadmap: []RoadmapPhase{{Name: "v2", Goals: []string{"Add a token"}}}}, []string{"token"}, true},
+		{"risks", Idea{Risks: []string{"Blockchain fees"}}, []string{"blockchain"}, true},
+		{"no keywords", Idea{Concept: "Anything"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mentionsExcluded(tt.idea, tt.keywords); got != tt.want {
+				t.Errorf("mentionsExcluded(%+v, %q) = %v, want %v", tt.idea, tt.keywords, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// maxExportIdeas caps how many ideas a single export can render.
+const maxExportIdeas = 50
+
+// pdfExportEnabled reports whether ENABLE_PDF_EXPORT is set. PDF export
+// pulls in a rendering library, so it stays off unless asked for.
+func pdfExportEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ENABLE_PDF_EXPORT"))
+	return enabled
+}
+
+// exportHandler serves POST /api/export?format=pdf. The body is an
+// IdeaResponse as returned by /api/generate-ideas. An optional ?locale=
+// controls how the generation timestamp is formatted.
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !pdfExportEnabled() {
+		writeError(w, r, "export is disabled", http.StatusNotFound)
+		return
+	}
+	if format := r.URL.Query().Get("format"); format != "pdf" {
+		writeError(w, r, fmt.Sprintf("unsupported export format %q: supported formats are pdf", format), http.StatusBadRequest)
+		return
+	}
+	locale, err := parseLocale(r.URL.Query().Get("locale"))
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var export IdeaResponse
+	if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(export.Ideas) == 0 {
+		writeError(w, r, "ideas must not be empty", http.StatusBadRequest)
+		return
+	}
+	if len(export.Ideas) > maxExportIdeas {
+		writeError(w, r, fmt.Sprintf("at most %d ideas can be exported", maxExportIdeas), http.StatusBadRequest)
+		return
+	}
+	for i := range export.Ideas {
+		if err := validateIdea(&export.Ideas[i], IdeaRequest{}); err != nil {
+			writeError(w, r, fmt.Sprintf("idea %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, err := renderIdeasPDF(export.Ideas, time.Now(), locale)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="ideas.pdf"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// renderIdeasPDF lays out one section per idea with its concept and a
+// bulleted feature list, with the timestamp formatted for locale. The core
+// fonts only cover Windows-1252, so other characters may not render.
+func renderIdeasPDF(ideas []Idea, generated time.Time, locale string) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("Project Ideas", true)
+	pdf.SetCreator(serviceName, true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 12, "Project Ideas", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 6, tr("Generated "+generated.UTC().Format(dateLayout(locale))), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	for i, idea := range ideas {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.MultiCell(0, 8, tr(fmt.Sprintf("%d. %s", i+1, idea.Name)), "", "L", false)
+
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 6, tr(idea.Concept), "", "L", false)
+		pdf.Ln(2)
+
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 6, "Features", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		for _, feature := range strings.Split(idea.Features, ",") {
+			if feature = strings.TrimSpace(feature); feature != "" {
+				pdf.SetX(pdf.GetX() + 4)
+				pdf.MultiCell(0, 6, tr("• "+feature), "", "L", false)
+			}
+		}
+		pdf.Ln(4)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
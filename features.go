@@ -0,0 +1,136 @@
+package main
+
+import "strings"
+
+// splitFeatures splits a comma-separated features string into trimmed,
+// non-empty features.
+func splitFeatures(features string) []string {
+	var out []string
+	for _, f := range strings.Split(features, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// normalizeFeature returns the key features are compared by: lowercased,
+// with whitespace collapsed and surrounding punctuation removed.
+func normalizeFeature(f string) string {
+	f = strings.Join(strings.Fields(strings.ToLower(f)), " ")
+	return strings.Trim(f, ".;:!-–—\"'")
+}
+
+// mergeCommonFeatures moves features that appear in more than one idea into
+// a shared list, leaving each idea with only its unique features. An idea
+// whose features are all shared keeps them, so no idea ends up without
+// features. Matching is case-insensitive after normalization; the shared
+// list keeps the wording of the first occurrence.
+func mergeCommonFeatures(ideas []Idea) []string {
+	seenIn := make(map[string]int)
+	first := make(map[string]string)
+	var order []string
+	for _, idea := range ideas {
+		inIdea := make(map[string]bool)
+		for _, f := range splitFeatures(idea.Features) {
+			key := normalizeFeature(f)
+			if key == "" || inIdea[key] {
+				continue
+			}
+			inIdea[key] = true
+			if _, ok := first[key]; !ok {
+				first[key] = strings.TrimRight(f, ".;")
+				order = append(order, key)
+			}
+			seenIn[key]++
+		}
+	}
+
+	common := []string{}
+	isCommon := make(map[string]bool)
+	for _, key := range order {
+		if seenIn[key] > 1 {
+			common = append(common, first[key])
+			isCommon[key] = true
+		}
+	}
+	if len(common) == 0 {
+		return common
+	}
+
+	for i := range ideas {
+		var unique []string
+		for _, f := range splitFeatures(ideas[i].Features) {
+			if !isCommon[normalizeFeature(f)] {
+				unique = append(unique, f)
+			}
+		}
+		if len(unique) == 0 {
+			continue
+		}
+		ideas[i].Features = strings.Join(unique, ", ")
+
+		details := ideas[i].FeatureDetails[:0]
+		for _, d := range ideas[i].FeatureDetails {
+			if !isCommon[normalizeFeature(d.Name)] {
+				details = append(details, d)
+			}
+		}
+		ideas[i].FeatureDetails = details
+	}
+	return common
+}
+
+// FeatureDependency records that Feature requires Requires to be built
+// first. Both name features of the same idea.
+type FeatureDependency struct {
+	Feature  string `json:"feature"`
+	Requires string `json:"requires"`
+}
+
+// cleanDependencies keeps the dependency edges that form a valid graph over
+// the idea's features. Edges naming unknown features, self-references,
+// duplicates and edges that would close a cycle are dropped, in the order
+// the model returned them.
+func cleanDependencies(idea Idea) []FeatureDependency {
+	names := make(map[string]string)
+	for _, f := range splitFeatures(idea.Features) {
+		names[normalizeFeature(f)] = f
+	}
+
+	requires := make(map[string][]string)
+	// reaches reports whether to can be reached from from along the edges
+	// accepted so far.
+	var reaches func(from, to string, seen map[string]bool) bool
+	reaches = func(from, to string, seen map[string]bool) bool {
+		if from == to {
+			return true
+		}
+		seen[from] = true
+		for _, next := range requires[from] {
+			if !seen[next] && reaches(next, to, seen) {
+				return true
+			}
+		}
+		return false
+	}
+
+	edges := []FeatureDependency{}
+	dup := make(map[[2]string]bool)
+	for _, d := range idea.Dependencies {
+		from, to := normalizeFeature(d.Feature), normalizeFeature(d.Requires)
+		if _, ok := names[from]; !ok {
+			continue
+		}
+		if _, ok := names[to]; !ok {
+			continue
+		}
+		if from == to || dup[[2]string{from, to}] || reaches(to, from, map[string]bool{}) {
+			continue
+		}
+		dup[[2]string{from, to}] = true
+		requires[from] = append(requires[from], to)
+		edges = append(edges, FeatureDependency{Feature: names[from], Requires: names[to]})
+	}
+	return edges
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeCommonFeatures(t *testing.T) {
+	ideas := []Idea{
+		{Features: "Login, Search, Maps"},
+		{Features: "login, search."},
+		{Features: "Chat"},
+	}
+	common := mergeCommonFeatures(ideas)
+	if want := []string{"Login", "Search"}; !slices.Equal(common, want) {
+		t.Errorf("common features = %q, want %q", common, want)
+	}
+	want := []string{"Maps", "login, search.", "Chat"}
+	for i, idea := range ideas {
+		if idea.Features != want[i] {
+			t.Errorf("idea %d features = %q, want %q", i, idea.Features, want[i])
+		}
+	}
+}
+
+func TestCleanDependencies(t *testing.T) {
+	idea := Idea{
+		Features: "Accounts, Reminders, Sharing, Insights",
+		Dependencies: []FeatureDependency{
+			{Feature: "Reminders", Requires: "Accounts"},
+			{Feature: "sharing", Requires: "reminders"},  // matched case-insensitively
+			{Feature: "Accounts", Requires: "Sharing"},   // closes a cycle
+			{Feature: "Reminders", Requires: "Accounts"}, // duplicate
+			{Feature: "Insights", Requires: "Insights"},  // self-reference
+			{Feature: "Insights", Requires: "Billing"},   // unknown feature
+			{Feature: "Insights", Requires: "Sharing"},
+		},
+	}
+	want := []FeatureDependency{
+		{Feature: "Reminders", Requires: "Accounts"},
+		{Feature: "Sharing", Requires: "Reminders"},
+		{Feature: "Insights", Requires: "Sharing"},
+	}
+	if got := cleanDependencies(idea); !slices.Equal(got, want) {
+		t.Errorf("cleanDependencies = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ideaStructFields lists the JSON names of Idea's fields in declaration
+// order, which is the default serialization order.
+var ideaStructFields = jsonFieldNames(reflect.TypeOf(Idea{}))
+
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ideaFieldOrder returns the order idea fields are serialized in. Fields
+// listed in FIELD_ORDER come first, in that order; the rest follow in
+// declaration order. Unknown names in FIELD_ORDER are ignored.
+func ideaFieldOrder() []string {
+	raw := os.Getenv("FIELD_ORDER")
+	if raw == "" {
+		return ideaStructFields
+	}
+
+	known := ideaFieldNames()
+	placed := make(map[string]bool)
+	var order []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if known[f] && !placed[f] {
+			order = append(order, f)
+			placed[f] = true
+		}
+	}
+	for _, f := range ideaStructFields {
+		if !placed[f] {
+			order = append(order, f)
+		}
+	}
+	return order
+}
+
+// jsonField is one key and its encoded value.
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// orderedObject is a JSON object that serializes its fields in slice
+// order, unlike a map whose keys encoding/json sorts.
+type orderedObject []jsonField
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ideaObject encodes idea as an orderedObject following ideaFieldOrder.
+// Fields omitted by omitempty stay omitted.
+func ideaObject(idea Idea) (orderedObject, error) {
+	type plain Idea
+	data, err := json.Marshal(plain(idea))
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	obj := make(orderedObject, 0, len(fields))
+	for _, key := range ideaFieldOrder() {
+		if v, ok := fields[key]; ok {
+			obj = append(obj, jsonField{key: key, value: v})
+		}
+	}
+	return obj, nil
+}
+
+// MarshalJSON writes idea's fields in the configured FIELD_ORDER, for
+// consumers that compare responses byte for byte. Without FIELD_ORDER the
+// output is the default struct encoding.
+func (i Idea) MarshalJSON() ([]byte, error) {
+	if os.Getenv("FIELD_ORDER") == "" {
+		type plain Idea
+		return json.Marshal(plain(i))
+	}
+	obj, err := ideaObject(i)
+	if err != nil {
+		return nil, err
+	}
+	return obj.MarshalJSON()
+}
@@ -3,6 +3,7 @@ module github.com/Siddheshk02/idea-generator
 go 1.22.3
 
 require (
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/joho/godotenv v1.5.1
 	github.com/rs/cors v1.11.1
 )
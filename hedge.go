@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+)
+
+// hedgeDelay returns how long to wait for the first upstream response
+// before sending a second, identical request. Hedging roughly doubles
+// upstream cost for slow requests, so it is off unless HEDGE_DELAY_MS is
+// set to a positive value.
+func hedgeDelay() (time.Duration, bool) {
+	ms, err := strconv.Atoi(os.Getenv("HEDGE_DELAY_MS"))
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// hedgeResult is the outcome of one of the hedged calls.
+type hedgeResult struct {
+	content string
+	err     error
+	hedge   bool
+}
+
+// callGroqHedged sends groqReq and, if no response has arrived after
+// delay, sends it again. The first successful response wins and the other
+// call is cancelled. A failure is only returned once every call in flight
+// has failed. Streamed requests are never hedged.
+func callGroqHedged(groqReq GroqRequest, delay time.Duration) (string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Buffered so the losing call can always deliver and exit.
+	results := make(chan hedgeResult, 2)
+	launch := func(hedge bool) {
+		go func() {
+			content, err := callGroqOnce(ctx, groqReq)
+			results <- hedgeResult{content: content, err: err, hedge: hedge}
+		}()
+	}
+
+	launch(false)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	hedged, pending := false, 1
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			hedged = true
+			pending++
+			metrics.Incr("upstream.hedge.fired")
+			launch(true)
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				if hedged {
+					winner := "primary"
+					if res.hedge {
+						winner = "hedge"
+					}
+					metrics.Incr("upstream.hedge.won", "winner:"+winner)
+				}
+				return res.content, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if pending == 0 {
+				return "", firstErr
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Policies for suspicious Unicode in domain and description, chosen with
+// UNICODE_POLICY. Detection is off by default.
+const (
+	unicodePolicyOff    = "off"
+	unicodePolicyReject = "reject"
+	unicodePolicyStrip  = "strip"
+)
+
+func unicodePolicy() string {
+	switch p := os.Getenv("UNICODE_POLICY"); p {
+	case unicodePolicyReject, unicodePolicyStrip:
+		return p
+	}
+	return unicodePolicyOff
+}
+
+// isBidiControl reports whether r is a bidirectional embedding, override
+// or isolate, which can reorder how text is displayed without changing its
+// content. The directional marks LRM, RLM and ALM are not included, as
+// ordinary Arabic and Hebrew text uses them.
+func isBidiControl(r rune) bool {
+	switch {
+	case r >= 0x202A && r <= 0x202E: // embeddings and overrides
+		return true
+	case r >= 0x2066 && r <= 0x2069: // isolates
+		return true
+	}
+	return false
+}
+
+// stripBidiControls returns s without bidi control characters and whether
+// any were found.
+func stripBidiControls(s string) (string, bool) {
+	found := false
+	cleaned := strings.Map(func(r rune) rune {
+		if isBidiControl(r) {
+			found = true
+			return -1
+		}
+		return r
+	}, s)
+	return cleaned, found
+}
+
+// confusableScripts are scripts whose letters are commonly mistaken for
+// Latin ones.
+var confusableScripts = []*unicode.RangeTable{unicode.Cyrillic, unicode.Greek}
+
+// hasMixedScriptWord reports whether any word in s mixes Latin letters
+// with letters from a confusable script, as in a homoglyph spoof. Words
+// written wholly in one script are fine.
+func hasMixedScriptWord(s string) bool {
+	for _, word := range strings.Fields(s) {
+		latin, confusable := false, false
+		for _, r := range word {
+			if unicode.Is(unicode.Latin, r) {
+				latin = true
+			} else if unicode.In(r, confusableScripts...) {
+				confusable = true
+			}
+		}
+		if latin && confusable {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnicode applies the UNICODE_POLICY to one request field. In reject
+// mode bidi controls are an error, in strip mode they are removed. Mixed
+// script words are never rejected, only reported as warnings.
+func checkUnicode(field string, value *string) (warnings []string, err error) {
+	policy := unicodePolicy()
+	if policy == unicodePolicyOff {
+		return nil, nil
+	}
+
+	cleaned, found := stripBidiControls(*value)
+	if found {
+		if policy == unicodePolicyReject {
+			return nil, fmt.Errorf("%s contains bidirectional control characters", field)
+		}
+		*value = cleaned
+		warnings = append(warnings, fmt.Sprintf("%s: bidirectional control characters removed", field))
+	}
+
+	if hasMixedScriptWord(*value) {
+		warnings = append(warnings, fmt.Sprintf("%s: mixes Latin with look-alike Cyrillic or Greek letters", field))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestCheckUnicodeRejectsOnlyBidiControls(t *testing.T) {
+	t.Setenv("UNICODE_POLICY", unicodePolicyReject)
+
+	for _, ok := range []string{
+		"تطبيق\u200f الصحة",   // RLM
+		"עברית\u200e English", // LRM
+		"٣\u061c٤",            // ALM
+	} {
+		value := ok
+		if _, err := checkUnicode("description", &value); err != nil || value != ok {
+			t.Errorf("checkUnicode(%q) = %q, %v; want it accepted unchanged", ok, value, err)
+		}
+	}
+	for _, bad := range []string{"abc\u202edcba", "x\u2066y\u2069"} {
+		value := bad
+		if _, err := checkUnicode("description", &value); err == nil {
+			t.Errorf("checkUnicode(%q) accepted a bidi control", bad)
+		}
+	}
+}
+
+func TestCheckUnicodeStripsBidiControls(t *testing.T) {
+	t.Setenv("UNICODE_POLICY", unicodePolicyStrip)
+
+	value := "abc\u202edcba\u200f"
+	warnings, err := checkUnicode("domain", &value)
+	if err != nil || value != "abcdcba\u200f" || len(warnings) != 1 {
+		t.Errorf("checkUnicode = %q, %q, %v; want the override stripped with one warning", value, warnings, err)
+	}
+}
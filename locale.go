@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// neutralDateLayout formats export timestamps when no locale is given or
+// the locale has no specific layout.
+const neutralDateLayout = "2006-01-02 15:04 MST"
+
+// localeDateLayouts maps locales, or just their language, to the layout
+// used for export timestamps. A full locale takes precedence over its
+// language.
+var localeDateLayouts = map[string]string{
+	"en-US": "January 2, 2006 3:04 PM MST",
+	"en":    "2 January 2006 15:04 MST",
+	"de":    "02.01.2006 15:04 MST",
+	"ru":    "02.01.2006 15:04 MST",
+	"fr":    "02/01/2006 15:04 MST",
+	"es":    "02/01/2006 15:04 MST",
+	"it":    "02/01/2006 15:04 MST",
+	"pt":    "02/01/2006 15:04 MST",
+	"nl":    "02-01-2006 15:04 MST",
+	"ja":    "2006/01/02 15:04 MST",
+	"zh":    "2006/01/02 15:04 MST",
+	"ko":    "2006/01/02 15:04 MST",
+}
+
+// localePattern accepts a language with an optional region, e.g. "de" or
+// "en-US". Underscores are accepted in place of the hyphen.
+var localePattern = regexp.MustCompile(`^([A-Za-z]{2,3})(?:[-_]([A-Za-z]{2}))?$`)
+
+// parseLocale validates and canonicalizes a locale, e.g. "en_us" becomes
+// "en-US". An empty locale is the neutral default.
+func parseLocale(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	m := localePattern.FindStringSubmatch(raw)
+	if m == nil {
+		return "", fmt.Errorf("invalid locale %q: expected a language with an optional region, e.g. en-US", raw)
+	}
+	locale := strings.ToLower(m[1])
+	if m[2] != "" {
+		locale += "-" + strings.ToUpper(m[2])
+	}
+	return locale, nil
+}
+
+// dateLayout returns the timestamp layout for locale.
+func dateLayout(locale string) string {
+	if layout, ok := localeDateLayouts[locale]; ok {
+		return layout
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	if layout, ok := localeDateLayouts[lang]; ok {
+		return layout
+	}
+	return neutralDateLayout
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"de", "de", false},
+		{"en-US", "en-US", false},
+		{"en_us", "en-US", false},
+		{" FR-ca ", "fr-CA", false},
+		{"fil", "fil", false},
+		{"english", "", true},
+		{"en-USA", "", true},
+		{"en--US", "", true},
+		{"de;rm -rf", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseLocale(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseLocale(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	at := time.Date(2026, 3, 4, 15, 5, 0, 0, time.UTC)
+	tests := map[string]string{
+		"":      "2026-03-04 15:05 UTC",
+		"en-US": "March 4, 2026 3:05 PM UTC",
+		"en-GB": "4 March 2026 15:05 UTC",
+		"de-AT": "04.03.2026 15:05 UTC",
+		"ja":    "2026/03/04 15:05 UTC",
+		"xx":    "2026-03-04 15:05 UTC",
+	}
+	for locale, want := range tests {
+		if got := at.Format(dateLayout(locale)); got != want {
+			t.Errorf("dateLayout(%q) formats as %q, want %q", locale, got, want)
+		}
+	}
+}
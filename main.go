@@ -2,31 +2,368 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 )
 
 type IdeaRequest struct {
-	Domain      string `json:"domain"`
-	Description string `json:"description"`
+	Domain              string `json:"domain"`
+	Description         string `json:"description"`
+	IncludeFollowups    bool   `json:"include_followups"`
+	IncludeSummary      bool   `json:"include_summary"`
+	FeaturesDetailed    bool   `json:"features_detailed"`
+	IncludeEffort       bool   `json:"include_effort"`
+	IncludeCompetitors  bool   `json:"include_competitors"`
+	FeatureDependencies bool   `json:"feature_dependencies"`
+	IncludeSWOT         bool   `json:"include_swot"`
+
+	// IncludeRisks asks for a short list of key risks per idea, a lighter
+	// alternative to include_swot.
+	IncludeRisks bool `json:"include_risks"`
+
+	// IncludeRoadmap asks for a phased roadmap per idea.
+	IncludeRoadmap bool `json:"include_roadmap"`
+
+	// IncludeAccessibility asks for accessibility considerations per idea.
+	IncludeAccessibility bool `json:"include_accessibility"`
+
+	// IncludeMarketSize asks for a rough market-size estimate per idea.
+	IncludeMarketSize bool `json:"include_market_size"`
+
+	// IncludeSafetyScore attaches a brand-safety score to every idea from a
+	// separate moderation pass. Nothing is dropped.
+	IncludeSafetyScore bool `json:"include_safety_score"`
+
+	// IncludeSkills asks for the key skills needed to build each idea.
+	IncludeSkills bool `json:"include_skills"`
+
+	// IncludeMonetization asks for a suggested monetization model per idea.
+	IncludeMonetization bool `json:"include_monetization"`
+
+	// MergeCommonFeatures lists features shared by several ideas once in
+	// meta instead of repeating them on every idea.
+	MergeCommonFeatures bool `json:"merge_common_features"`
+
+	// Trendy biases ideas toward current technology trends, optionally
+	// steered by TrendHints.
+	Trendy     bool     `json:"trendy"`
+	TrendHints []string `json:"trend_hints"`
+
+	// ExcludeKeywords steers the model away from these concepts, and ideas
+	// mentioning any of them are dropped.
+	ExcludeKeywords []string `json:"exclude_keywords"`
+
+	// Budget constrains ideas to what can be built within it, e.g. "$0".
+	Budget string `json:"budget"`
+
+	// FormatTemplate is a template, with [placeholders], that concepts and
+	// features should follow, e.g. "As a [user], I want [feature] so that
+	// [benefit]".
+	FormatTemplate string `json:"format_template"`
+
+	// ConceptSentences is the number of sentences each concept should have.
+	// Zero leaves the length unconstrained.
+	ConceptSentences int `json:"concept_sentences"`
+
+	// RankBy is a criterion the model scores ideas by, best first.
+	RankBy string `json:"rank_by"`
+
+	// StripEmojis removes emoji from the output. When unset the server
+	// default from STRIP_EMOJIS applies.
+	StripEmojis *bool `json:"strip_emojis"`
+
+	// MaxNameChars caps idea names, in characters. When unset the server
+	// default from MAX_NAME_CHARS applies.
+	MaxNameChars *int `json:"max_name_chars"`
+
+	// Seed makes sampling reproducible where upstream supports it. When
+	// unset the server default from DEFAULT_SEED applies.
+	Seed *int64 `json:"seed"`
+
+	// PromptVersion overrides the pinned prompt version. Admin only.
+	PromptVersion string `json:"prompt_version"`
+	// IncludePrompt returns the messages sent upstream in meta. Admin only.
+	IncludePrompt bool `json:"include_prompt"`
+	// VerifyDeterminism generates twice and reports in meta whether the
+	// outputs matched. It needs a seed and temperature 0. Admin only.
+	VerifyDeterminism bool `json:"verify_determinism"`
+
+	// strictness is the prompt strictness level chosen for this request.
+	strictness int
+	// model is the upstream model that produced the generation.
+	model string
+	// inferredDomain is the domain inferred from the description, if any.
+	inferredDomain string
+	// debug logs the request's steps when an admin sets X-Debug.
+	debug *debugLog
+	// mockScenario selects the canned output used in mock mode.
+	mockScenario string
+	// inputWarnings are reported in meta for suspicious but accepted input.
+	inputWarnings []string
+}
+
+// minNameChars and maxNameChars bound max_name_chars.
+const (
+	minNameChars = 3
+	maxNameChars = 200
+)
+
+// maxRankByLen caps the length of the rank_by criterion.
+const maxRankByLen = 60
+
+// maxBudgetLen caps the length of the budget constraint.
+const maxBudgetLen = 60
+
+// maxFormatTemplateLen caps the length of format_template.
+const maxFormatTemplateLen = 200
+
+// maxTrendHints caps how many trend hints are injected into the prompt.
+const maxTrendHints = 5
+
+// maxTrendHintLen caps the length of a single trend hint.
+const maxTrendHintLen = 40
+
+// normalize sanitizes the free-text options that end up in the prompt and
+// fills in server defaults. It returns an error for requests that cannot
+// be served.
+func (r *IdeaRequest) normalize() error {
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{{"domain", &r.Domain}, {"description", &r.Description}} {
+		warnings, err := checkUnicode(f.name, f.value)
+		if err != nil {
+			return err
+		}
+		r.inputWarnings = append(r.inputWarnings, warnings...)
+	}
+
+	if r.MaxNameChars == nil {
+		if n, err := strconv.Atoi(os.Getenv("MAX_NAME_CHARS")); err == nil && n > 0 {
+			r.MaxNameChars = &n
+		}
+	}
+	if r.MaxNameChars != nil && (*r.MaxNameChars < minNameChars || *r.MaxNameChars > maxNameChars) {
+		return fmt.Errorf("max_name_chars must be between %d and %d", minNameChars, maxNameChars)
+	}
+
+	if r.Seed == nil {
+		if seed, err := strconv.ParseInt(os.Getenv("DEFAULT_SEED"), 10, 64); err == nil {
+			r.Seed = &seed
+		}
+	}
+
+	if r.ConceptSentences < 0 || r.ConceptSentences > maxConceptSentences {
+		return fmt.Errorf("concept_sentences must be between 0 and %d", maxConceptSentences)
+	}
+
+	if r.MergeCommonFeatures && r.FeatureDependencies {
+		return fmt.Errorf("merge_common_features cannot be combined with feature_dependencies")
+	}
+
+	keywords, err := cleanExcludeKeywords(r.ExcludeKeywords)
+	if err != nil {
+		return err
+	}
+	r.ExcludeKeywords = keywords
+
+	if r.RankBy != "" {
+		if len([]rune(r.RankBy)) > maxRankByLen {
+			return fmt.Errorf("rank_by must be at most %d characters", maxRankByLen)
+		}
+		r.RankBy = sanitizePromptText(r.RankBy, maxRankByLen)
+	}
+
+	if r.Budget != "" {
+		if len([]rune(r.Budget)) > maxBudgetLen {
+			return fmt.Errorf("budget must be at most %d characters", maxBudgetLen)
+		}
+		r.Budget = sanitizePromptText(r.Budget, maxBudgetLen)
+	}
+
+	if r.FormatTemplate != "" {
+		if len([]rune(r.FormatTemplate)) > maxFormatTemplateLen {
+			return fmt.Errorf("format_template must be at most %d characters", maxFormatTemplateLen)
+		}
+		r.FormatTemplate = sanitizePromptText(r.FormatTemplate, maxFormatTemplateLen)
+	}
+
+	if r.Trendy {
+		hints := r.TrendHints
+		if len(hints) == 0 {
+			hints = strings.Split(os.Getenv("TREND_HINTS"), ",")
+		}
+		r.TrendHints = nil
+		for _, h := range hints {
+			if h = sanitizePromptText(h, maxTrendHintLen); h != "" {
+				r.TrendHints = append(r.TrendHints, h)
+			}
+			if len(r.TrendHints) == maxTrendHints {
+				break
+			}
+		}
+	} else {
+		r.TrendHints = nil
+	}
+	return nil
+}
+
+// stripsEmojis reports whether emoji should be removed from the output.
+func (r IdeaRequest) stripsEmojis() bool {
+	if r.StripEmojis != nil {
+		return *r.StripEmojis
+	}
+	strip, _ := strconv.ParseBool(os.Getenv("STRIP_EMOJIS"))
+	return strip
+}
+
+// wantsEnvelope reports whether the model should return an object wrapping
+// the ideas array rather than the bare array.
+func (r IdeaRequest) wantsEnvelope() bool {
+	return r.IncludeFollowups || r.IncludeSummary || r.strictness >= strictnessJSONMode
 }
 
 type Idea struct {
-	Name     string `json:"name"`
-	Concept  string `json:"concept"`
-	Features string `json:"features"`
+	Name                  string              `json:"name"`
+	Concept               string              `json:"concept"`
+	Features              string              `json:"features"`
+	FeatureDetails        []FeatureDetail     `json:"features_detailed,omitempty"`
+	Effort                string              `json:"effort,omitempty"`
+	Competitors           []string            `json:"competitors,omitempty"`
+	Dependencies          []FeatureDependency `json:"dependencies,omitempty"`
+	SWOT                  *SWOT               `json:"swot,omitempty"`
+	Risks                 []string            `json:"risks,omitempty"`
+	Accessibility         []string            `json:"accessibility,omitempty"`
+	Skills                []string            `json:"skills,omitempty"`
+	Roadmap               []RoadmapPhase      `json:"roadmap,omitempty"`
+	Monetization          string              `json:"monetization,omitempty"`
+	MonetizationRationale string              `json:"monetization_rationale,omitempty"`
+	MarketSize            string              `json:"market_size,omitempty"`
+	Score                 *float64            `json:"score,omitempty"`
+	Relevance             *float64            `json:"relevance,omitempty"`
+	SafetyScore           *SafetyScore        `json:"safety_score,omitempty"`
+	OffTopic              bool                `json:"off_topic,omitempty"`
+}
+
+// SWOT is a short strengths, weaknesses, opportunities and threats analysis
+// of an idea, returned when the request sets include_swot.
+type SWOT struct {
+	Strengths     []string `json:"strengths"`
+	Weaknesses    []string `json:"weaknesses"`
+	Opportunities []string `json:"opportunities"`
+	Threats       []string `json:"threats"`
+}
+
+// RoadmapPhase is one phase of an idea's roadmap, returned when the request
+// sets include_roadmap.
+type RoadmapPhase struct {
+	Name  string   `json:"name"`
+	Goals []string `json:"goals"`
+}
+
+// FeatureDetail is a single feature together with why it matters, returned
+// when the request sets features_detailed.
+type FeatureDetail struct {
+	Name string `json:"name"`
+	Why  string `json:"why"`
+}
+
+// UnmarshalJSON accepts 'features' either as the flat comma-separated string
+// or as a list of FeatureDetail objects. In the latter case Features is
+// filled with the joined feature names so flat consumers keep working.
+func (i *Idea) UnmarshalJSON(data []byte) error {
+	type plain Idea
+	aux := struct {
+		*plain
+		Features json.RawMessage `json:"features"`
+	}{plain: (*plain)(i)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.Features) == 0 || string(aux.Features) == "null" {
+		return nil
+	}
+	if aux.Features[0] == '"' {
+		return json.Unmarshal(aux.Features, &i.Features)
+	}
+
+	if err := json.Unmarshal(aux.Features, &i.FeatureDetails); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(i.FeatureDetails))
+	for _, f := range i.FeatureDetails {
+		names = append(names, f.Name)
+	}
+	i.Features = strings.Join(names, ", ")
+	return nil
 }
 
 type IdeaResponse struct {
-	Ideas []Idea `json:"ideas"`
+	Ideas []Idea    `json:"ideas"`
+	Meta  *IdeaMeta `json:"meta,omitempty"`
+}
+
+// DeterminismCheck reports whether two generations with the same seed and
+// temperature 0 matched. Outputs holds both raw outputs when they differ.
+type DeterminismCheck struct {
+	Identical bool     `json:"identical"`
+	Outputs   []string `json:"outputs,omitempty"`
+}
+
+// compareOutputs compares the raw model outputs of two generations.
+func compareOutputs(first, second string) *DeterminismCheck {
+	if first == second {
+		return &DeterminismCheck{Identical: true}
+	}
+	metrics.Incr("determinism.mismatch")
+	return &DeterminismCheck{Outputs: []string{first, second}}
+}
+
+// IdeaMeta carries optional information about a generation alongside the
+// ideas themselves. It is omitted from the response when empty.
+type IdeaMeta struct {
+	PromptVersion        string            `json:"prompt_version,omitempty"`
+	Disclaimer           string            `json:"disclaimer,omitempty"`
+	MarketSizeDisclaimer string            `json:"market_size_disclaimer,omitempty"`
+	Seed                 *int64            `json:"seed,omitempty"`
+	Summary              string            `json:"summary,omitempty"`
+	Budget               string            `json:"budget,omitempty"`
+	FormatTemplate       string            `json:"format_template,omitempty"`
+	RankBy               string            `json:"rank_by,omitempty"`
+	CommonFeatures       []string          `json:"common_features,omitempty"`
+	TrendHints           []string          `json:"trend_hints,omitempty"`
+	InputWarnings        []string          `json:"input_warnings,omitempty"`
+	Prompt               []GroqMessage     `json:"prompt,omitempty"`
+	ModelRemap           *ModelRemap       `json:"model_remap,omitempty"`
+	Determinism          *DeterminismCheck `json:"determinism,omitempty"`
+	Followups            []string          `json:"followups,omitempty"`
+	InferredDomain       string            `json:"inferred_domain,omitempty"`
+	OffTopicDropped      int               `json:"off_topic_dropped,omitempty"`
+	ExcludedDropped      int               `json:"excluded_dropped,omitempty"`
+}
+
+// meta returns the response meta, allocating it on first use.
+func (r *IdeaResponse) meta() *IdeaMeta {
+	if r.Meta == nil {
+		r.Meta = &IdeaMeta{}
+	}
+	return r.Meta
 }
 
 type GroqClient struct {
@@ -46,12 +383,27 @@ type GroqRequest struct {
 	TopP        float64       `json:"top_p"`
 	Stream      bool          `json:"stream"`
 	Stop        any           `json:"stop"`
+
+	ResponseFormat *GroqResponseFormat `json:"response_format,omitempty"`
+	Seed           *int64              `json:"seed,omitempty"`
+
+	// mockContent is returned instead of calling upstream in mock mode.
+	mockContent string
+}
+
+// GroqResponseFormat enables Groq's JSON mode when Type is "json_object".
+type GroqResponseFormat struct {
+	Type string `json:"type"`
 }
 
 func main() {
 
 	_ = godotenv.Load()
 
+	metrics = newMetricsFromEnv()
+	recentErrors = newErrorRingFromEnv()
+	strictness = newStrictnessControllerFromEnv()
+
 	allowedOrigins := strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
 	if len(allowedOrigins) == 0 || (len(allowedOrigins) == 1 && allowedOrigins[0] == "") {
 		allowedOrigins = []string{"http://localhost:3000"} // Fallback for local development
@@ -60,7 +412,8 @@ func main() {
 	c := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept", mockScenarioHeader, adminTokenHeader, debugHeader, requestIDHeader},
+		ExposedHeaders:   []string{disclaimerHeader, requestIDHeader},
 		AllowCredentials: true,
 		Debug:            true, // Enable for debugging, remove in production
 	})
@@ -72,7 +425,29 @@ func main() {
 			generateIdeasHandler(w, r)
 			return
 		}
-		http.NotFound(w, r)
+		if r.URL.Path == "/api/generate-ideas/from-file" {
+			generateIdeasFromFileHandler(w, r)
+			return
+		}
+		if r.URL.Path == "/api/translate" {
+			translateHandler(w, r)
+			return
+		}
+		if r.URL.Path == "/api/export" {
+			exportHandler(w, r)
+			return
+		}
+		if r.URL.Path == "/api/admin/errors" {
+			adminErrorsHandler(w, r)
+			return
+		}
+		if r.URL.Path == "/api/admin/strictness" {
+			adminStrictnessHandler(w, r)
+			return
+		}
+		// Unknown paths are mostly scanner probes; recording them would push
+		// the upstream errors out of the admin errors buffer.
+		writeErrorBody(w, r, "404 page not found", http.StatusNotFound)
 	}))
 
 	port := os.Getenv("PORT")
@@ -86,123 +461,963 @@ func main() {
 
 func generateIdeasHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost && r.Method != http.MethodOptions {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req IdeaRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	serveIdeas(w, r, req)
+}
+
+// serveIdeas validates req, generates ideas for it and writes the response
+// in the format asked for by the query string.
+func serveIdeas(w http.ResponseWriter, r *http.Request, req IdeaRequest) {
+	req.debug = newDebugLog(w, r)
+	if err := req.normalize(); err != nil {
+		req.debug.Printf("rejected request: %v", err)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.debug != nil {
+		data, _ := json.Marshal(req)
+		req.debug.Printf("normalized request: %s", data)
+	}
+
+	if req.PromptVersion != "" {
+		if !isAdmin(r) {
+			writeError(w, r, "prompt_version requires admin access", http.StatusForbidden)
+			return
+		}
+		if _, ok := promptVersions[req.PromptVersion]; !ok {
+			writeError(w, r, fmt.Sprintf("unknown prompt_version: %s", req.PromptVersion), http.StatusBadRequest)
+			return
+		}
+	} else {
+		req.PromptVersion = defaultPromptVersion()
+	}
+
+	if req.IncludePrompt && !isAdmin(r) {
+		writeError(w, r, "include_prompt requires admin access", http.StatusForbidden)
+		return
+	}
+
+	if req.VerifyDeterminism {
+		if !isAdmin(r) {
+			writeError(w, r, "verify_determinism requires admin access", http.StatusForbidden)
+			return
+		}
+		if req.Seed == nil || defaultTemperature() != 0 {
+			writeError(w, r, "verify_determinism requires a seed and temperature 0", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if mockEnabled() {
+		req.mockScenario = r.Header.Get(mockScenarioHeader)
+		if req.mockScenario == "" {
+			req.mockScenario = mockValid
+		}
+		if !mockScenarios[req.mockScenario] {
+			writeError(w, r, fmt.Sprintf("unknown %s: %s", mockScenarioHeader, req.mockScenario), http.StatusBadRequest)
+			return
+		}
+	}
+
+	fields, err := parseFieldsParam(r.URL.Query().Get("fields"))
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	ideas, err := generateIdeas(req.Domain, req.Description)
+	// Inference runs before the split so streamed requests get it too.
+	if domainInferenceEnabled() && strings.TrimSpace(req.Domain) == "" && strings.TrimSpace(req.Description) != "" {
+		domain, err := inferDomain(req.Description)
+		if err != nil {
+			metrics.Incr("requests", "status:error")
+			writeError(w, r, fmt.Sprintf("failed to infer domain: %v", err), http.StatusInternalServerError)
+			return
+		}
+		req.Domain = domain
+		req.inferredDomain = domain
+	}
+
+	if r.URL.Query().Get("stream") == "json" {
+		streamIdeasHandler(w, r, req, fields)
+		return
+	}
+
+	response, err := generateIdeas(req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		metrics.Incr("requests", "status:error")
+		writeError(w, r, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	metrics.Incr("requests", "status:ok")
 
-	response := IdeaResponse{Ideas: ideas}
 	w.Header().Set("Content-Type", "application/json")
+	if len(fields) > 0 {
+		sparse, err := filterIdeaFields(response.Ideas, fields)
+		if err != nil {
+			writeError(w, r, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(SparseIdeaResponse{Ideas: sparse, Meta: response.Meta})
+		return
+	}
+
 	json.NewEncoder(w).Encode(response)
 }
 
-func generateIdeas(domain, description string) ([]Idea, error) {
-	apiKey := os.Getenv("GROQ_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("GROQ_API_KEY not set")
+// SparseIdeaResponse is returned instead of IdeaResponse when the client
+// asks for a subset of fields via ?fields=.
+type SparseIdeaResponse struct {
+	Ideas []orderedObject `json:"ideas"`
+	Meta  *IdeaMeta       `json:"meta,omitempty"`
+}
+
+// ideaFieldNames returns the JSON names of the fields an Idea can carry.
+func ideaFieldNames() map[string]bool {
+	names := make(map[string]bool, len(ideaStructFields))
+	for _, name := range ideaStructFields {
+		names[name] = true
+	}
+	return names
+}
+
+// parseFieldsParam parses a comma-separated ?fields= value. Unknown fields
+// are dropped, or rejected when FIELDS_STRICT is set.
+func parseFieldsParam(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
 	}
 
-	groqReq := GroqRequest{
-		Model: "llama3-8b-8192",
-		Messages: []GroqMessage{
-			{
-				Role:    "system",
-				Content: "You are an AI assistant that generates project ideas. Your output must be a valid JSON array of objects, each with exactly three fields: 'name', 'concept', and 'features'. The 'features' field must be a single string with comma-separated values. Do not include any explanation or additional text. Generate exactly 5 ideas based on this format: [{'name': 'Project Name', 'concept': 'Short description', 'features': 'Feature 1, Feature 2, Feature 3'}]. Ensure the JSON array is properly closed with a square bracket ']' at the end.",
-			},
-			{
-				Role:    "user",
-				Content: fmt.Sprintf("Generate 3 project ideas for the domain: %s. Description: %s", domain, description),
-			},
-		},
-		Temperature: 0.7,
+	strict, _ := strconv.ParseBool(os.Getenv("FIELDS_STRICT"))
+	known := ideaFieldNames()
+
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !known[f] {
+			if strict {
+				return nil, fmt.Errorf("unknown field: %s", f)
+			}
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// filterIdeaFields keeps only the requested fields of each idea, in the
+// configured field order.
+func filterIdeaFields(ideas []Idea, fields []string) ([]orderedObject, error) {
+	wanted := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		wanted[f] = true
+	}
+
+	sparse := make([]orderedObject, 0, len(ideas))
+	for _, idea := range ideas {
+		obj, err := ideaObject(idea)
+		if err != nil {
+			return nil, err
+		}
+
+		picked := make(orderedObject, 0, len(fields))
+		for _, f := range obj {
+			if wanted[f.key] {
+				picked = append(picked, f)
+			}
+		}
+		sparse = append(sparse, picked)
+	}
+	return sparse, nil
+}
+
+const systemPrompt = "You are an AI assistant that generates project ideas. Your output must be a valid JSON array of objects, each with exactly three fields: 'name', 'concept', and 'features'. The 'features' field must be a single string with comma-separated values. Do not include any explanation or additional text. Generate exactly 5 ideas based on this format: [{'name': 'Project Name', 'concept': 'Short description', 'features': 'Feature 1, Feature 2, Feature 3'}]. Ensure the JSON array is properly closed with a square bracket ']' at the end."
+
+// serviceName and serviceVersion identify this service to upstream APIs.
+const (
+	serviceName    = "idea-generator"
+	serviceVersion = "1.0.0"
+)
+
+// upstreamUserAgent returns the User-Agent sent to Groq, overridable via
+// GROQ_USER_AGENT.
+func upstreamUserAgent() string {
+	if ua := os.Getenv("GROQ_USER_AGENT"); ua != "" {
+		return ua
+	}
+	return serviceName + "/" + serviceVersion
+}
+
+// ideaCount is the number of ideas generated per request.
+const ideaCount = 3
+
+// maxFollowups caps how many follow-up prompts are returned in meta.
+const maxFollowups = 3
+
+func generateIdeas(req IdeaRequest) (IdeaResponse, error) {
+	req.strictness = strictness.Level(activeModel(defaultModel))
+
+	groqReq := newGenerationRequest(req)
+	req.debug.Printf("upstream request: model=%s strictness=%d temperature=%g messages=%+v", groqReq.Model, req.strictness, groqReq.Temperature, groqReq.Messages)
+	start := time.Now()
+	content, err := callGroq(groqReq)
+	if err != nil {
+		req.debug.Printf("upstream call failed after %s: %v", time.Since(start), err)
+		return IdeaResponse{}, err
+	}
+	req.model = activeModel(groqReq.Model)
+	req.debug.Printf("upstream response from %s after %s: %s", req.model, time.Since(start), content)
+
+	var determinism *DeterminismCheck
+	if req.VerifyDeterminism {
+		second, err := callGroq(groqReq)
+		if err != nil {
+			return IdeaResponse{}, fmt.Errorf("failed to verify determinism: %v", err)
+		}
+		determinism = compareOutputs(content, second)
+	}
+
+	response, err := parseGeneration(content, req)
+	if errors.Is(err, errConceptSentences) {
+		// A concept of the wrong length gets one fresh attempt.
+		req.debug.Printf("re-rolling: %v", err)
+		if content, err = callGroq(groqReq); err == nil {
+			req.debug.Printf("re-rolled upstream response: %s", content)
+			response, err = parseGeneration(content, req)
+		}
+	}
+	strictness.Record(activeModel(defaultModel), err == nil)
+	if err != nil {
+		req.debug.Printf("parse failed: %v", err)
+		metrics.Incr("parse_failures")
+		return IdeaResponse{}, err
+	}
+	req.debug.Printf("parsed %d ideas", len(response.Ideas))
+
+	response.meta().PromptVersion = req.PromptVersion
+	response.meta().Disclaimer = disclaimer()
+	if req.IncludeMarketSize {
+		response.meta().MarketSizeDisclaimer = marketSizeDisclaimer
+	}
+	response.meta().Seed = req.Seed
+	response.meta().TrendHints = req.TrendHints
+	response.meta().Budget = req.Budget
+	response.meta().FormatTemplate = req.FormatTemplate
+	response.meta().InputWarnings = req.inputWarnings
+	if req.IncludePrompt {
+		response.meta().Prompt = groqReq.Messages
+	}
+	response.meta().ModelRemap = modelRemap()
+	response.meta().Determinism = determinism
+	response.meta().InferredDomain = req.inferredDomain
+
+	kept := response.Ideas[:0]
+	excluded := 0
+	for i := range response.Ideas {
+		if mentionsExcluded(response.Ideas[i], req.ExcludeKeywords) {
+			excluded++
+			continue
+		}
+		if checkRelevance(&response.Ideas[i], req) {
+			kept = append(kept, response.Ideas[i])
+		}
+	}
+	if dropped := len(response.Ideas) - len(kept) - excluded; dropped > 0 {
+		response.meta().OffTopicDropped = dropped
+	}
+	if excluded > 0 {
+		response.meta().ExcludedDropped = excluded
+	}
+	req.debug.Printf("kept %d of %d ideas after filtering (%d excluded)", len(kept), len(response.Ideas), excluded)
+	response.Ideas = kept
+
+	if req.IncludeSafetyScore {
+		scoreSafety(response.Ideas, req)
+	}
+
+	if req.RankBy != "" {
+		sort.SliceStable(response.Ideas, func(i, j int) bool {
+			return *response.Ideas[i].Score > *response.Ideas[j].Score
+		})
+		response.meta().RankBy = req.RankBy
+	}
+
+	if req.MergeCommonFeatures {
+		response.meta().CommonFeatures = mergeCommonFeatures(response.Ideas)
+	}
+	return response, nil
+}
+
+// defaultDisclaimer is returned when DISCLAIMER_ENABLED is set without a
+// custom DISCLAIMER_TEXT.
+const defaultDisclaimer = "AI-generated content. Verify independently before relying on it."
+
+// disclaimerHeader carries the disclaimer on responses that have no meta
+// to hold it, such as ?stream=json.
+const disclaimerHeader = "X-Disclaimer"
+
+// disclaimer returns the disclaimer to attach to every generation, or ""
+// when DISCLAIMER_ENABLED is not set.
+func disclaimer() string {
+	if enabled, _ := strconv.ParseBool(os.Getenv("DISCLAIMER_ENABLED")); !enabled {
+		return ""
+	}
+	if text := strings.TrimSpace(os.Getenv("DISCLAIMER_TEXT")); text != "" {
+		return text
+	}
+	return defaultDisclaimer
+}
+
+// domainInferenceEnabled reports whether a missing domain may be inferred
+// from the description. It costs an extra upstream call, so it is opt-in
+// via ENABLE_DOMAIN_INFERENCE.
+func domainInferenceEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ENABLE_DOMAIN_INFERENCE"))
+	return enabled
+}
+
+// maxInferredDomainLen caps the length of a domain inferred by the model.
+const maxInferredDomainLen = 60
+
+// inferDomain makes a single short call asking the model to name the
+// domain a description belongs to.
+func inferDomain(description string) (string, error) {
+	groqReq := newGroqRequest([]GroqMessage{
+		{Role: "system", Content: "You classify project descriptions. Reply with only the name of the domain the description belongs to, in two or three words (for example: e-commerce, healthcare, developer tools). Do not add punctuation or any other text."},
+		{Role: "user", Content: description},
+	})
+	groqReq.Temperature = 0
+	groqReq.MaxTokens = 16
+
+	content, err := callGroq(groqReq)
+	if err != nil {
+		return "", err
+	}
+
+	domain, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	domain = strings.Trim(strings.TrimSpace(domain), "\"'.`")
+	if r := []rune(domain); len(r) > maxInferredDomainLen {
+		domain = string(r[:maxInferredDomainLen])
+	}
+	if domain == "" {
+		return "", fmt.Errorf("model returned an empty domain")
+	}
+	return domain, nil
+}
+
+// buildMessages assembles the system and user messages for a generation
+// request, including any opt-in instructions.
+func buildMessages(req IdeaRequest) []GroqMessage {
+	system := promptVersions[req.PromptVersion]
+	if system == "" {
+		system = promptVersions[fallbackPromptVersion]
+	}
+	if req.wantsEnvelope() {
+		system += " Instead of a bare array, wrap the output in a JSON object of the form {\"ideas\": [...]} where 'ideas' holds the array described above."
+	}
+	if req.strictness >= strictnessFirm {
+		system += " IMPORTANT: respond with raw JSON only. Do not wrap it in markdown code fences, do not add comments, and do not write anything before or after the JSON."
+	}
+	if req.FeaturesDetailed {
+		system += " Override for the 'features' field: it must be an array of objects, each with a 'name' (short feature name) and a 'why' (one sentence explaining the value of the feature), e.g. [{'name': 'Feature 1', 'why': 'Why it matters'}]."
+	}
+	if extra := extraFieldInstructions(req); len(extra) > 0 {
+		system += " In addition to those three fields, each idea object must also include: " + strings.Join(extra, "; ") + "."
+	}
+
+	userPrompt := fmt.Sprintf("Generate %d project ideas for the domain: %s. Description: %s", ideaCount, req.Domain, req.Description)
+	if vocab := domainVocabulary(req.Domain); vocab != "" {
+		userPrompt += fmt.Sprintf(" Where it fits, prefer this standard feature vocabulary for the domain (guidance only, not a hard requirement): %s.", vocab)
+	}
+	if req.MaxNameChars != nil {
+		userPrompt += fmt.Sprintf(" Keep each idea's name short: at most %d characters.", *req.MaxNameChars)
+	}
+	if req.Budget != "" {
+		userPrompt += fmt.Sprintf(" Budget constraint: only suggest ideas that can realistically be built and launched within this budget: %q.", req.Budget)
+	}
+	if req.FormatTemplate != "" {
+		userPrompt += fmt.Sprintf(" Write each concept, and each feature, to follow this template, replacing the bracketed placeholders with specifics: %q.", req.FormatTemplate)
+	}
+	if len(req.ExcludeKeywords) > 0 {
+		userPrompt += fmt.Sprintf(" Do not suggest ideas that involve or mention any of these: %s.", quoteList(req.ExcludeKeywords))
+	}
+	if req.ConceptSentences > 0 {
+		userPrompt += fmt.Sprintf(" Write each concept in exactly %d sentence(s).", req.ConceptSentences)
+	}
+	if req.Trendy {
+		userPrompt += " Bias the ideas toward current technology trends so they feel cutting edge."
+		if len(req.TrendHints) > 0 {
+			userPrompt += fmt.Sprintf(" Consider these trends where they make sense: %s.", strings.Join(req.TrendHints, ", "))
+		}
+	}
+	if req.IncludeFollowups {
+		userPrompt += fmt.Sprintf(" Also add a 'followups' field to the object: an array of up to %d short follow-up prompts (a domain and description the user might try next), each a single string.", maxFollowups)
+	}
+	if req.IncludeSummary {
+		userPrompt += " Also add a 'summary' field to the object: a single paragraph that ties the ideas together and names the themes they share."
+	}
+
+	return []GroqMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
+// extraFieldInstructions describes the opt-in per-idea fields the request
+// asked for, one instruction per field.
+func extraFieldInstructions(req IdeaRequest) []string {
+	var extra []string
+	if req.IncludeEffort {
+		extra = append(extra, fmt.Sprintf("'effort', a rough build-time estimate for a small team, exactly one of: %s", quoteList(effortValues)))
+	}
+	if req.IncludeCompetitors {
+		extra = append(extra, fmt.Sprintf("'competitors', an array of up to %d existing products similar to the idea; only name products you are confident exist, and if you know of none use [%q] instead of inventing any", maxCompetitors, noCompetitorsKnown))
+	}
+	if req.FeatureDependencies {
+		extra = append(extra, "'dependencies', an array of objects {'feature': ..., 'requires': ...} saying which of the idea's features must be built before another, using the exact feature names from 'features' and never forming a cycle")
+	}
+	if req.IncludeSWOT {
+		extra = append(extra, fmt.Sprintf("'swot', an object with 'strengths', 'weaknesses', 'opportunities' and 'threats', each an array of up to %d short strings", maxSWOTItems))
+	}
+	if req.IncludeRisks {
+		extra = append(extra, fmt.Sprintf("'risks', an array of up to %d key risks or challenges the idea faces, each a short, specific and realistic sentence rather than a generic concern", maxRisks))
+	}
+	if req.IncludeRoadmap {
+		extra = append(extra, fmt.Sprintf("'roadmap', an array of phase objects {'name': ..., 'goals': [...]} for the phases %s in that order, each with up to %d short goals", quoteList(roadmapPhases), maxRoadmapGoals))
+	}
+	if req.IncludeAccessibility {
+		extra = append(extra, fmt.Sprintf("'accessibility', an array of up to %d short accessibility considerations specific to the idea, e.g. 'screen-reader support' or 'high-contrast mode'", maxAccessibilityItems))
+	}
+	if req.IncludeMarketSize {
+		extra = append(extra, fmt.Sprintf("'market_size', a rough TAM-style market-size estimate of at most %d characters that starts with %q; give a broad order of magnitude and never invent precise figures or cite sources you cannot verify", maxMarketSizeLen, marketSizeCaveat))
+	}
+	if req.IncludeSkills {
+		extra = append(extra, fmt.Sprintf("'skills', an array of up to %d key skills a team needs to build the idea, each a short name such as 'React', 'ML' or 'design'", maxSkills))
+	}
+	if req.IncludeMonetization {
+		extra = append(extra, fmt.Sprintf("'monetization', how the idea would most plausibly make money, exactly one of: %s, and 'monetization_rationale', one short sentence on why that model fits", quoteList(monetizationValues)))
+	}
+	if req.RankBy != "" {
+		extra = append(extra, fmt.Sprintf("'score', a number from 0 to %d rating the idea on this criterion: %q", maxScore, req.RankBy))
+	}
+	return extra
+}
+
+// quoteList formats values as a comma-separated list of quoted strings.
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// defaultModel is the Groq model used for generation.
+const defaultModel = "llama3-8b-8192"
+
+// defaultTemperature returns DEFAULT_TEMPERATURE, or 0.7 when it is unset
+// or outside Groq's 0-2 range.
+func defaultTemperature() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("DEFAULT_TEMPERATURE"), 64); err == nil && v >= 0 && v <= 2 {
+		return v
+	}
+	return 0.7
+}
+
+// newGroqRequest returns a chat completion request for messages with the
+// default generation settings.
+func newGroqRequest(messages []GroqMessage) GroqRequest {
+	return GroqRequest{
+		Model:       activeModel(defaultModel),
+		Messages:    messages,
+		Temperature: defaultTemperature(),
 		MaxTokens:   1240,
 		TopP:        1,
 		Stream:      false,
 		Stop:        nil,
 	}
+}
+
+// newGenerationRequest builds the upstream request for generating ideas
+// for req.
+func newGenerationRequest(req IdeaRequest) GroqRequest {
+	groqReq := newGroqRequest(buildMessages(req))
+	if req.strictness >= strictnessJSONMode {
+		groqReq.ResponseFormat = &GroqResponseFormat{Type: "json_object"}
+	}
+	groqReq.Seed = req.Seed
+	attachMock(&groqReq, req)
+	return groqReq
+}
+
+// doGroq sends groqReq to the Groq chat completions API. The caller must
+// close the response body.
+func doGroq(ctx context.Context, groqReq GroqRequest) (*http.Response, error) {
+	if mockEnabled() {
+		return mockGroqResponse(groqReq), nil
+	}
+
+	apiKey := os.Getenv("GROQ_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GROQ_API_KEY not set")
+	}
 
 	jsonData, err := json.Marshal(groqReq)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.groq.com/openai/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.groq.com/openai/v1/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("User-Agent", upstreamUserAgent())
 
 	client := &http.Client{}
+	start := time.Now()
 	resp, err := client.Do(req)
+	metrics.Timing("upstream.latency", time.Since(start))
 	if err != nil {
+		metrics.Incr("upstream.errors")
 		return nil, err
 	}
+	return resp, nil
+}
+
+// callGroq sends groqReq to the Groq chat completions API and returns the
+// content of the first choice, hedging the call when HEDGE_DELAY_MS is set.
+func callGroq(groqReq GroqRequest) (string, error) {
+	content, err := callGroqDirect(groqReq)
+	if err != nil {
+		return retryOnSuccessor(groqReq, err, callGroqDirect)
+	}
+	return content, nil
+}
+
+// callGroqDirect calls the model named in groqReq, hedging when configured.
+func callGroqDirect(groqReq GroqRequest) (string, error) {
+	if delay, ok := hedgeDelay(); ok {
+		return callGroqHedged(groqReq, delay)
+	}
+	return callGroqOnce(context.Background(), groqReq)
+}
+
+// callGroqOnce makes a single chat completion call.
+func callGroqOnce(ctx context.Context, groqReq GroqRequest) (string, error) {
+	resp, err := doGroq(ctx, groqReq)
+	if err != nil {
+		return "", err
+	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var result map[string]interface{}
 	err = json.Unmarshal(body, &result)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if err := upstreamModelError(groqReq.Model, result); err != nil {
+		return "", err
 	}
 
 	choices, ok := result["choices"].([]interface{})
 	if !ok || len(choices) == 0 {
-		return nil, fmt.Errorf("unexpected response format")
+		return "", fmt.Errorf("unexpected response format")
 	}
 
 	firstChoice, ok := choices[0].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("unexpected choice format")
+		return "", fmt.Errorf("unexpected choice format")
 	}
 
 	message, ok := firstChoice["message"].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("unexpected message format")
+		return "", fmt.Errorf("unexpected message format")
 	}
 
 	content, ok := message["content"].(string)
 	if !ok {
-		return nil, fmt.Errorf("unexpected content format")
+		return "", fmt.Errorf("unexpected content format")
 	}
 
-	return parseIdeas(content)
+	return content, nil
+}
+
+// generationEnvelope is the object the model returns when the request asks
+// for more than the bare ideas array.
+type generationEnvelope struct {
+	Ideas     json.RawMessage `json:"ideas"`
+	Followups []string        `json:"followups"`
+	Summary   string          `json:"summary"`
 }
 
-func parseIdeas(content string) ([]Idea, error) {
+// parseGeneration parses model output into a response, unwrapping the
+// envelope object when the request asked for one.
+func parseGeneration(content string, req IdeaRequest) (IdeaResponse, error) {
+	if !req.wantsEnvelope() {
+		ideas, err := parseIdeas(content, req)
+		if err != nil {
+			return IdeaResponse{}, err
+		}
+		return IdeaResponse{Ideas: ideas}, nil
+	}
+
+	var env generationEnvelope
+	if err := json.Unmarshal([]byte(content), &env); err != nil {
+		return IdeaResponse{}, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	ideas, err := parseIdeas(string(env.Ideas), req)
+	if err != nil {
+		return IdeaResponse{}, err
+	}
+
+	response := IdeaResponse{Ideas: ideas}
+	if req.IncludeFollowups {
+		response.meta().Followups = cleanList(env.Followups, maxFollowups)
+	}
+	if req.IncludeSummary {
+		summary := strings.TrimSpace(env.Summary)
+		if summary == "" {
+			return IdeaResponse{}, fmt.Errorf("invalid response format: summary is missing")
+		}
+		response.meta().Summary = summary
+	}
+	return response, nil
+}
+
+// cleanList trims the items of a model-provided list, drops empty ones and
+// keeps at most max of them.
+func cleanList(raw []string, max int) []string {
+	items := []string{}
+	for _, item := range raw {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		items = append(items, item)
+		if len(items) == max {
+			break
+		}
+	}
+	return items
+}
+
+// defaultDomainVocabularies maps normalized domains to the feature
+// vocabulary suggested to the model for that domain.
+var defaultDomainVocabularies = map[string]string{
+	"e-commerce": "cart, checkout, wishlist, product catalog, order tracking",
+	"fintech":    "accounts, transactions, budgeting, KYC, notifications",
+	"healthcare": "appointments, patient records, prescriptions, telehealth",
+	"education":  "courses, quizzes, progress tracking, certificates",
+}
+
+// normalizeDomain lowercases a domain and collapses its whitespace so that
+// lookups keyed on the domain are forgiving of user formatting.
+func normalizeDomain(domain string) string {
+	return strings.Join(strings.Fields(strings.ToLower(domain)), " ")
+}
+
+// domainVocabulary returns the feature vocabulary hint for a domain, or ""
+// when none is configured. DOMAIN_VOCABULARIES may hold a JSON object of
+// domain to vocabulary that extends or overrides the defaults.
+func domainVocabulary(domain string) string {
+	key := normalizeDomain(domain)
+
+	if raw := os.Getenv("DOMAIN_VOCABULARIES"); raw != "" {
+		var custom map[string]string
+		if err := json.Unmarshal([]byte(raw), &custom); err != nil {
+			log.Printf("ignoring invalid DOMAIN_VOCABULARIES: %v", err)
+		} else {
+			for d, vocab := range custom {
+				if normalizeDomain(d) == key {
+					return vocab
+				}
+			}
+		}
+	}
+
+	return defaultDomainVocabularies[key]
+}
+
+func parseIdeas(content string, req IdeaRequest) ([]Idea, error) {
 	var ideas []Idea
 	err := json.Unmarshal([]byte(content), &ideas)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %v", err)
 	}
 
-	if len(ideas) != 3 {
-		return nil, fmt.Errorf("expected 3 ideas, got %d", len(ideas))
+	if err := checkIdeaCount(len(ideas), req.model); err != nil {
+		return nil, err
+	}
+	if len(ideas) > ideaCount {
+		ideas = ideas[:ideaCount]
 	}
 
-	for _, idea := range ideas {
-		if idea.Name == "" || idea.Concept == "" || idea.Features == "" {
-			return nil, fmt.Errorf("invalid idea format: all fields must be non-empty")
+	for i := range ideas {
+		if err := validateIdea(&ideas[i], req); err != nil {
+			return nil, err
 		}
 	}
 
 	return ideas, nil
 }
+
+// validateIdea checks a single parsed idea against the request and strips
+// anything the request did not ask for.
+func validateIdea(idea *Idea, req IdeaRequest) error {
+	if req.stripsEmojis() {
+		stripIdeaEmojis(idea)
+	}
+	// Relevance and safety are computed server-side, never taken from the
+	// model.
+	idea.Relevance = nil
+	idea.OffTopic = false
+	idea.SafetyScore = nil
+	if req.MaxNameChars != nil {
+		idea.Name = truncateName(idea.Name, *req.MaxNameChars)
+	}
+
+	if idea.Name == "" || idea.Concept == "" || idea.Features == "" {
+		return fmt.Errorf("invalid idea format: all fields must be non-empty")
+	}
+
+	if req.ConceptSentences > 0 {
+		if err := fitConceptSentences(idea, req.ConceptSentences); err != nil {
+			return err
+		}
+	}
+
+	if !req.FeaturesDetailed {
+		idea.FeatureDetails = nil
+	} else {
+		if len(idea.FeatureDetails) == 0 {
+			return fmt.Errorf("invalid idea format: features must be a list of objects")
+		}
+		for _, f := range idea.FeatureDetails {
+			if f.Name == "" || f.Why == "" {
+				return fmt.Errorf("invalid idea format: each feature needs a name and why")
+			}
+		}
+	}
+
+	if req.IncludeEffort {
+		idea.Effort = normalizeEnum(idea.Effort, effortValues, "unknown")
+	} else {
+		idea.Effort = ""
+	}
+
+	if req.IncludeCompetitors {
+		idea.Competitors = cleanList(idea.Competitors, maxCompetitors)
+		if len(idea.Competitors) == 0 {
+			idea.Competitors = []string{noCompetitorsKnown}
+		}
+	} else {
+		idea.Competitors = nil
+	}
+
+	if req.FeatureDependencies {
+		idea.Dependencies = cleanDependencies(*idea)
+	} else {
+		idea.Dependencies = nil
+	}
+
+	if req.IncludeSWOT {
+		// Missing quadrants, or a missing block, default to empty lists.
+		swot := SWOT{}
+		if idea.SWOT != nil {
+			swot = *idea.SWOT
+		}
+		idea.SWOT = &SWOT{
+			Strengths:     cleanList(swot.Strengths, maxSWOTItems),
+			Weaknesses:    cleanList(swot.Weaknesses, maxSWOTItems),
+			Opportunities: cleanList(swot.Opportunities, maxSWOTItems),
+			Threats:       cleanList(swot.Threats, maxSWOTItems),
+		}
+	} else {
+		idea.SWOT = nil
+	}
+
+	if req.IncludeRisks {
+		idea.Risks = cleanList(idea.Risks, maxRisks)
+	} else {
+		idea.Risks = nil
+	}
+
+	if req.IncludeRoadmap {
+		idea.Roadmap = cleanRoadmap(idea.Roadmap)
+	} else {
+		idea.Roadmap = nil
+	}
+
+	if req.IncludeAccessibility {
+		idea.Accessibility = cleanList(idea.Accessibility, maxAccessibilityItems)
+	} else {
+		idea.Accessibility = nil
+	}
+
+	if req.IncludeMarketSize {
+		idea.MarketSize = cleanMarketSize(idea.MarketSize)
+	} else {
+		idea.MarketSize = ""
+	}
+
+	if req.IncludeSkills {
+		idea.Skills = cleanSkills(idea.Skills)
+	} else {
+		idea.Skills = nil
+	}
+
+	if req.IncludeMonetization {
+		idea.Monetization = normalizeEnum(idea.Monetization, monetizationValues, "other")
+		idea.MonetizationRationale = strings.TrimSpace(idea.MonetizationRationale)
+	} else {
+		idea.Monetization = ""
+		idea.MonetizationRationale = ""
+	}
+
+	if req.RankBy != "" {
+		// A missing score ranks last rather than failing the request.
+		score := 0.0
+		if idea.Score != nil {
+			score = math.Max(0, math.Min(maxScore, *idea.Score))
+		}
+		idea.Score = &score
+	} else {
+		idea.Score = nil
+	}
+
+	return nil
+}
+
+// maxCompetitors caps the competitors listed per idea.
+const maxCompetitors = 5
+
+// noCompetitorsKnown is returned in place of an empty competitors list.
+const noCompetitorsKnown = "none known"
+
+// maxSWOTItems caps the entries in each SWOT quadrant.
+const maxSWOTItems = 5
+
+// maxRisks caps the risks listed per idea.
+const maxRisks = 5
+
+// roadmapPhases are the phases of a roadmap, in order.
+var roadmapPhases = []string{"MVP", "v1", "v2"}
+
+// maxRoadmapGoals caps the goals listed per roadmap phase.
+const maxRoadmapGoals = 5
+
+// cleanRoadmap puts the model's phases in roadmapPhases order and drops any
+// others. A missing phase defaults to no goals.
+func cleanRoadmap(phases []RoadmapPhase) []RoadmapPhase {
+	roadmap := make([]RoadmapPhase, len(roadmapPhases))
+	for i, name := range roadmapPhases {
+		roadmap[i] = RoadmapPhase{Name: name, Goals: []string{}}
+		for _, p := range phases {
+			if strings.EqualFold(strings.TrimSpace(p.Name), name) {
+				roadmap[i].Goals = cleanList(p.Goals, maxRoadmapGoals)
+				break
+			}
+		}
+	}
+	return roadmap
+}
+
+// maxAccessibilityItems caps the accessibility considerations per idea.
+const maxAccessibilityItems = 5
+
+// maxScore is the top of the scale ideas are scored on for rank_by.
+const maxScore = 10
+
+// effortValues are the build-time estimates the model may choose from.
+var effortValues = []string{"weekend", "1 week", "2 weeks", "1 month", "3+ months"}
+
+// maxMarketSizeLen caps the market_size estimate, in characters.
+const maxMarketSizeLen = 120
+
+// marketSizeCaveat starts every market_size estimate.
+const marketSizeCaveat = "Rough estimate:"
+
+// marketSizeDisclaimer is reported in meta when market sizes are returned.
+const marketSizeDisclaimer = "Market sizes are rough, model-generated estimates, not researched figures."
+
+// cleanMarketSize caps a market-size estimate and makes sure it starts with
+// the rough estimate caveat.
+func cleanMarketSize(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return marketSizeCaveat + " unknown"
+	}
+	if strings.HasPrefix(strings.ToLower(s), strings.ToLower(marketSizeCaveat)) {
+		s = strings.TrimSpace(s[len(marketSizeCaveat):])
+	}
+	s = marketSizeCaveat + " " + s
+	return truncateName(s, maxMarketSizeLen)
+}
+
+// monetizationValues are the monetization models the model may choose
+// from. Anything else is reported as "other".
+var monetizationValues = []string{"freemium", "subscription", "ads", "one-time purchase", "transaction fees", "marketplace commission", "licensing", "sponsorship", "other"}
+
+// normalizeEnum returns the allowed value matching v case-insensitively, or
+// fallback when v is not one of them.
+func normalizeEnum(v string, allowed []string, fallback string) string {
+	v = strings.TrimSpace(v)
+	for _, a := range allowed {
+		if strings.EqualFold(v, a) {
+			return a
+		}
+	}
+	return fallback
+}
+
+// danglingWords are not left at the end of a truncated name.
+var danglingWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true,
+	"for": true, "to": true, "with": true, "in": true, "on": true, "by": true,
+	"&": true, "+": true, "-": true, "–": true, "—": true, "|": true, ":": true,
+}
+
+// truncateName shortens name to at most max characters, cutting on a word
+// boundary and dropping connector words and separators left dangling at
+// the end. A single word longer than max is cut mid-word as a last resort.
+func truncateName(name string, max int) string {
+	name = strings.TrimSpace(name)
+	runes := []rune(name)
+	if len(runes) <= max {
+		return name
+	}
+
+	words := strings.Fields(string(runes[:max]))
+	// The last word is partial unless the cut fell next to a space.
+	if !unicode.IsSpace(runes[max-1]) && !unicode.IsSpace(runes[max]) && len(words) > 0 {
+		words = words[:len(words)-1]
+	}
+	for len(words) > 0 && danglingWords[strings.ToLower(words[len(words)-1])] {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return string(runes[:max])
+	}
+	return strings.TrimRight(strings.Join(words, " "), ",;:-")
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTruncateName(t *testing.T) {
+	tests := []struct {
+		name, in string
+		max      int
+		want     string
+	}{
+		{"fits", "Plant Pal", 20, "Plant Pal"},
+		{"word boundary", "Smart Plant Watering Assistant", 20, "Smart Plant Watering"},
+		{"cut mid word", "Smart Plant Watering Assistant", 16, "Smart Plant"},
+		{"cut next to a space", "Smart Plant Watering", 12, "Smart Plant"},
+		{"trailing connector", "Tools for the Modern Gardener", 14, "Tools"},
+		{"trailing separator", "Plantly - Smart Watering", 10, "Plantly"},
+		{"single long word", "Supercalifragilistic", 8, "Supercal"},
+		{"surrounding space", "  Plant Pal  ", 20, "Plant Pal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateName(tt.in, tt.max); got != tt.want {
+				t.Errorf("truncateName(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+			}
+			if n := len([]rune(truncateName(tt.in, tt.max))); n > tt.max {
+				t.Errorf("result has %d characters, more than %d", n, tt.max)
+			}
+		})
+	}
+}
+
+func TestBuildMessagesIncludesMaxNameChars(t *testing.T) {
+	max := 24
+	msgs := buildMessages(IdeaRequest{Domain: "gardening", MaxNameChars: &max})
+	if !strings.Contains(msgs[1].Content, "at most 24 characters") {
+		t.Errorf("user prompt lacks the max_name_chars hint: %q", msgs[1].Content)
+	}
+
+	msgs = buildMessages(IdeaRequest{Domain: "gardening"})
+	if strings.Contains(msgs[1].Content, "characters") {
+		t.Errorf("user prompt has a name length hint without max_name_chars: %q", msgs[1].Content)
+	}
+}
+
+func TestNormalizeEnum(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"weekend", "weekend"},
+		{"  Weekend ", "weekend"},
+		{"1 MONTH", "1 month"},
+		{"a fortnight", "unknown"},
+		{"", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := normalizeEnum(tt.in, effortValues, "unknown"); got != tt.want {
+			t.Errorf("normalizeEnum(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeMonetization(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Subscription", "subscription"},
+		{" one-time PURCHASE ", "one-time purchase"},
+		{"Donations", "other"},
+		{"", "other"},
+	}
+	for _, tt := range tests {
+		if got := normalizeEnum(tt.in, monetizationValues, "other"); got != tt.want {
+			t.Errorf("normalizeEnum(%q, monetizationValues) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCleanRoadmap(t *testing.T) {
+	got := cleanRoadmap([]RoadmapPhase{
+		{Name: "v2", Goals: []string{"Teams"}},
+		{Name: " mvp ", Goals: []string{" Reminders ", "", "Tips"}},
+		{Name: "v9", Goals: []string{"Unknown phase"}},
+	})
+	want := []RoadmapPhase{
+		{Name: "MVP", Goals: []string{"Reminders", "Tips"}},
+		{Name: "v1", Goals: []string{}},
+		{Name: "v2", Goals: []string{"Teams"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("cleanRoadmap returned %d phases, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || !slices.Equal(got[i].Goals, want[i].Goals) || got[i].Goals == nil {
+			t.Errorf("phase %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCleanMarketSize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "Rough estimate: unknown"},
+		{"About $5B", "Rough estimate: About $5B"},
+		{"Rough estimate: About $5B", "Rough estimate: About $5B"},
+		{" rough ESTIMATE:  About $5B", "Rough estimate: About $5B"},
+		{"About $5B, not a rough estimate at all", "Rough estimate: About $5B, not a rough estimate at all"},
+	}
+	for _, tt := range tests {
+		if got := cleanMarketSize(tt.in); got != tt.want {
+			t.Errorf("cleanMarketSize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metrics is the instrumentation sink used by the request path. Emitters
+// implement it so call sites stay the same whichever backend is enabled.
+type Metrics interface {
+	Incr(name string, tags ...string)
+	Timing(name string, d time.Duration, tags ...string)
+	Gauge(name string, value float64, tags ...string)
+}
+
+// metrics is the process-wide emitter, set up in main.
+var metrics Metrics = noopMetrics{}
+
+type noopMetrics struct{}
+
+func (noopMetrics) Incr(string, ...string)                  {}
+func (noopMetrics) Timing(string, time.Duration, ...string) {}
+func (noopMetrics) Gauge(string, float64, ...string)        {}
+
+// newMetricsFromEnv returns a StatsD emitter when STATSD_ADDR is set and a
+// no-op emitter otherwise.
+func newMetricsFromEnv() Metrics {
+	addr := os.Getenv("STATSD_ADDR")
+	if addr == "" {
+		return noopMetrics{}
+	}
+
+	prefix := os.Getenv("STATSD_PREFIX")
+	if prefix == "" {
+		prefix = "idea_generator."
+	}
+	tags, _ := strconv.ParseBool(os.Getenv("STATSD_TAGS"))
+
+	m, err := newStatsdMetrics(addr, prefix, tags)
+	if err != nil {
+		log.Printf("statsd disabled: %v", err)
+		return noopMetrics{}
+	}
+	return m
+}
+
+// statsdMetrics pushes metrics over UDP in StatsD line format. When tags is
+// set, tags are appended in the DogStatsD "|#k:v" extension.
+type statsdMetrics struct {
+	conn   net.Conn
+	prefix string
+	tags   bool
+}
+
+func newStatsdMetrics(addr, prefix string, tags bool) (*statsdMetrics, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &statsdMetrics{conn: conn, prefix: prefix, tags: tags}, nil
+}
+
+func (m *statsdMetrics) Incr(name string, tags ...string) {
+	m.send(name, "1", "c", tags)
+}
+
+func (m *statsdMetrics) Timing(name string, d time.Duration, tags ...string) {
+	m.send(name, strconv.FormatInt(d.Milliseconds(), 10), "ms", tags)
+}
+
+func (m *statsdMetrics) Gauge(name string, value float64, tags ...string) {
+	m.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+func (m *statsdMetrics) send(name, value, kind string, tags []string) {
+	line := fmt.Sprintf("%s%s:%s|%s", m.prefix, name, value, kind)
+	if m.tags && len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	// UDP is fire-and-forget; a dropped metric must never fail a request.
+	_, _ = m.conn.Write([]byte(line))
+}
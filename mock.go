@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// mockScenarioHeader selects the canned upstream output used in mock mode.
+const mockScenarioHeader = "X-Mock-Scenario"
+
+// Mock scenarios reproduce the upstream outputs the parser has to cope with.
+const (
+	mockValid      = "valid"
+	mockTruncated  = "truncated"
+	mockFenced     = "fenced"
+	mockMalformed  = "malformed"
+	mockWrongCount = "wrong-count"
+)
+
+var mockScenarios = map[string]bool{
+	mockValid:      true,
+	mockTruncated:  true,
+	mockFenced:     true,
+	mockMalformed:  true,
+	mockWrongCount: true,
+}
+
+// mockEnabled reports whether GROQ_MOCK is set, in which case no upstream
+// calls are made and canned completions are returned instead.
+func mockEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("GROQ_MOCK"))
+	return enabled
+}
+
+// attachMock stores the canned completion for req's scenario on groqReq
+// when mock mode is on.
+func attachMock(groqReq *GroqRequest, req IdeaRequest) {
+	if mockEnabled() {
+		groqReq.mockContent = mockFixture(req)
+	}
+}
+
+// mockFixture builds the completion content for req's scenario. The valid
+// fixture honours the request's options so it always parses.
+func mockFixture(req IdeaRequest) string {
+	count := ideaCount
+	if req.mockScenario == mockWrongCount {
+		count = ideaCount + 2
+	}
+
+	ideas := make([]map[string]any, count)
+	for i := range ideas {
+		idea := map[string]any{
+			"name":     fmt.Sprintf("Mock Idea %d", i+1),
+			"concept":  fmt.Sprintf("A canned idea for %s used in mock mode.", req.Domain),
+			"features": "Feature 1, Feature 2, Feature 3",
+		}
+		if req.ConceptSentences > 1 {
+			idea["concept"] = idea["concept"].(string) + strings.Repeat(" It is canned.", req.ConceptSentences-1)
+		}
+		if req.FeaturesDetailed {
+			idea["features"] = []map[string]string{
+				{"name": "Feature 1", "why": "It matters."},
+				{"name": "Feature 2", "why": "It also matters."},
+			}
+		}
+		if req.IncludeEffort {
+			idea["effort"] = effortValues[i%len(effortValues)]
+		}
+		if req.IncludeCompetitors {
+			idea["competitors"] = []string{"Mock Competitor"}
+		}
+		if req.FeatureDependencies {
+			idea["dependencies"] = []map[string]string{{"feature": "Feature 2", "requires": "Feature 1"}}
+		}
+		if req.IncludeSWOT {
+			idea["swot"] = map[string][]string{
+				"strengths":     {"Simple to build"},
+				"weaknesses":    {"Crowded market"},
+				"opportunities": {"Growing demand"},
+			}
+		}
+		if req.IncludeRisks {
+			idea["risks"] = []string{"Users may not pay for a mock"}
+		}
+		if req.IncludeRoadmap {
+			idea["roadmap"] = []map[string]any{
+				{"name": "MVP", "goals": []string{"Ship the core feature"}},
+				{"name": "v1", "goals": []string{"Add accounts"}},
+			}
+		}
+		if req.IncludeAccessibility {
+			idea["accessibility"] = []string{"screen-reader support"}
+		}
+		if req.IncludeMarketSize {
+			idea["market_size"] = marketSizeCaveat + " a niche mock market"
+		}
+		if req.IncludeSkills {
+			idea["skills"] = []string{"Go", "design"}
+		}
+		if req.IncludeMonetization {
+			idea["monetization"] = monetizationValues[i%len(monetizationValues)]
+			idea["monetization_rationale"] = "It suits a mock audience."
+		}
+		if req.RankBy != "" {
+			idea["score"] = i + 1
+		}
+		ideas[i] = idea
+	}
+
+	var payload any = ideas
+	if req.wantsEnvelope() {
+		env := map[string]any{"ideas": ideas}
+		if req.IncludeFollowups {
+			env["followups"] = []string{"Try the same domain for students", "Try a B2B angle"}
+		}
+		if req.IncludeSummary {
+			env["summary"] = "Canned ideas that share a mock theme."
+		}
+		payload = env
+	}
+
+	data, _ := json.Marshal(payload)
+	content := string(data)
+
+	switch req.mockScenario {
+	case mockTruncated:
+		return content[:len(content)/2]
+	case mockFenced:
+		return "```json\n" + content + "\n```"
+	case mockMalformed:
+		return strings.ReplaceAll(content, `"`, "'")
+	}
+	return content
+}
+
+// mockGroqResponse returns a canned upstream response carrying groqReq's
+// mock content, as a server-sent event stream when streaming.
+func mockGroqResponse(groqReq GroqRequest) *http.Response {
+	content := groqReq.mockContent
+	if content == "" {
+		content = "mock"
+	}
+
+	var body string
+	if groqReq.Stream {
+		var b strings.Builder
+		for len(content) > 0 {
+			n := min(len(content), 16)
+			chunk, _ := json.Marshal(map[string]any{
+				"choices": []any{map[string]any{"delta": map[string]string{"content": content[:n]}}},
+			})
+			fmt.Fprintf(&b, "data: %s\n\n", chunk)
+			content = content[n:]
+		}
+		b.WriteString("data: [DONE]\n\n")
+		body = b.String()
+	} else {
+		data, _ := json.Marshal(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+		body = string(data)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
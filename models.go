@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// modelGoneCodes are the upstream error codes Groq returns for a model that
+// has been removed or was never available.
+var modelGoneCodes = map[string]bool{
+	"model_not_found":      true,
+	"model_decommissioned": true,
+}
+
+// modelGoneError reports that the upstream no longer serves a model.
+type modelGoneError struct {
+	Model   string
+	Message string
+}
+
+func (e *modelGoneError) Error() string {
+	return fmt.Sprintf("model %s unavailable upstream: %s", e.Model, e.Message)
+}
+
+// upstreamModelError returns a modelGoneError when an upstream response body
+// carries a model-not-found or decommissioned error.
+func upstreamModelError(model string, result map[string]interface{}) error {
+	e, ok := result["error"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	code, _ := e["code"].(string)
+	message, _ := e["message"].(string)
+	if !modelGoneCodes[code] && !strings.Contains(strings.ToLower(message), "decommissioned") {
+		return nil
+	}
+	return &modelGoneError{Model: model, Message: message}
+}
+
+// modelSuccessor returns the model to use once the default has been
+// retired, configured by MODEL_SUCCESSOR. Empty disables remapping.
+func modelSuccessor() string {
+	return strings.TrimSpace(os.Getenv("MODEL_SUCCESSOR"))
+}
+
+// retiredModels records models the upstream has reported as gone, so later
+// requests go straight to the successor.
+var retiredModels sync.Map
+
+// activeModel returns the model to send upstream in place of model.
+func activeModel(model string) string {
+	successor := modelSuccessor()
+	if successor == "" {
+		return model
+	}
+	if _, retired := retiredModels.Load(model); retired {
+		return successor
+	}
+	return model
+}
+
+// retryOnSuccessor re-sends groqReq on the successor model when err says its
+// model is gone and a successor is configured.
+func retryOnSuccessor(groqReq GroqRequest, err error, call func(GroqRequest) (string, error)) (string, error) {
+	var gone *modelGoneError
+	successor := modelSuccessor()
+	if !errors.As(err, &gone) || successor == "" || successor == groqReq.Model {
+		return "", err
+	}
+	if _, seen := retiredModels.LoadOrStore(groqReq.Model, true); !seen {
+		log.Printf("WARNING: upstream reports model %s is gone (%s); remapping to %s. Update the configured model.", groqReq.Model, gone.Message, successor)
+	}
+	metrics.Incr("upstream.model_remapped")
+	groqReq.Model = successor
+	return call(groqReq)
+}
+
+// ModelRemap records that a request was served by a successor model.
+type ModelRemap struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// modelRemap returns the remap in effect for the default model, if any.
+func modelRemap() *ModelRemap {
+	if model := activeModel(defaultModel); model != defaultModel {
+		return &ModelRemap{From: defaultModel, To: model}
+	}
+	return nil
+}
+
+// modelInfo describes how a model behaves upstream.
+type modelInfo struct {
+	// CountTolerance is how many ideas the model may return over or under
+	// ideaCount and still be accepted. Extra ideas are dropped.
+	CountTolerance int
+}
+
+// modelRegistry holds what is known about the models the service uses.
+// Models not listed get the zero modelInfo, which requires exact counts.
+var modelRegistry = map[string]modelInfo{
+	defaultModel: {CountTolerance: 0},
+}
+
+// countTolerance returns the idea count tolerance for model.
+// MODEL_COUNT_TOLERANCE, a comma-separated list of model=n pairs,
+// overrides the registry.
+func countTolerance(model string) int {
+	for _, pair := range strings.Split(os.Getenv("MODEL_COUNT_TOLERANCE"), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) != model {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 || n >= ideaCount {
+			log.Printf("ignoring invalid MODEL_COUNT_TOLERANCE entry %q", pair)
+			continue
+		}
+		return n
+	}
+	return modelRegistry[model].CountTolerance
+}
+
+// checkIdeaCount reports whether got ideas from model is within its count
+// tolerance of ideaCount.
+func checkIdeaCount(got int, model string) error {
+	tolerance := countTolerance(model)
+	if got < ideaCount-tolerance || got > ideaCount+tolerance {
+		if tolerance == 0 {
+			return fmt.Errorf("expected %d ideas, got %d", ideaCount, got)
+		}
+		return fmt.Errorf("expected %d±%d ideas, got %d", ideaCount, tolerance, got)
+	}
+	if got != ideaCount {
+		log.Printf("accepted %d ideas from %s within count tolerance %d", got, model, tolerance)
+	}
+	return nil
+}
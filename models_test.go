@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrictnessFollowsRemappedModel(t *testing.T) {
+	t.Setenv("ADAPTIVE_STRICTNESS", "true")
+	t.Setenv("PARSE_STRICTNESS_WINDOW", "5")
+	t.Setenv("MODEL_SUCCESSOR", "successor-model")
+	retiredModels.Store(defaultModel, true)
+	t.Cleanup(func() { retiredModels.Delete(defaultModel) })
+	orig := strictness
+	strictness = newStrictnessControllerFromEnv()
+	t.Cleanup(func() { strictness = orig })
+
+	sent := stubUpstream(t, "not json")
+	for i := 0; i < 5; i++ {
+		if _, err := generateIdeas(IdeaRequest{Domain: "gardening"}); err == nil {
+			t.Fatal("generateIdeas succeeded on unparseable output")
+		}
+	}
+
+	generateIdeas(IdeaRequest{Domain: "gardening"})
+	if !strings.Contains(*sent, "respond with raw JSON only") {
+		t.Errorf("request after repeated parse failures was not escalated: %s", *sent)
+	}
+}
+
+func TestCheckIdeaCount(t *testing.T) {
+	exact := defaultModel
+	if err := checkIdeaCount(ideaCount, exact); err != nil {
+		t.Errorf("exact count rejected: %v", err)
+	}
+	if err := checkIdeaCount(ideaCount+1, exact); err == nil {
+		t.Error("count over an exact model's tolerance accepted")
+	}
+
+	t.Setenv("MODEL_COUNT_TOLERANCE", "loose-model=1, other=bad")
+	for _, n := range []int{ideaCount - 1, ideaCount, ideaCount + 1} {
+		if err := checkIdeaCount(n, "loose-model"); err != nil {
+			t.Errorf("count %d rejected within tolerance: %v", n, err)
+		}
+	}
+	for _, n := range []int{ideaCount - 2, ideaCount + 2} {
+		if err := checkIdeaCount(n, "loose-model"); err == nil {
+			t.Errorf("count %d accepted outside tolerance", n)
+		}
+	}
+	if got := countTolerance("other"); got != 0 {
+		t.Errorf("invalid override gave tolerance %d, want the registry's 0", got)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// problemContentType is the RFC 7807 media type for problem details.
+const problemContentType = "application/problem+json"
+
+// problem is an RFC 7807 problem details body. Code is a stable
+// machine-readable error code alongside the standard members.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// problemCodes maps the statuses the service returns to stable error
+// codes. The problem type URI is derived from the code.
+var problemCodes = map[int]string{
+	http.StatusBadRequest:            "invalid_request",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusInternalServerError:   "generation_failed",
+}
+
+// problemTypeBase prefixes problem type URIs, configured by
+// PROBLEM_TYPE_BASE. The default is a relative reference.
+func problemTypeBase() string {
+	if base := os.Getenv("PROBLEM_TYPE_BASE"); base != "" {
+		return strings.TrimSuffix(base, "/") + "/"
+	}
+	return "/problems/"
+}
+
+// wantsProblemDetails reports whether errors for r are written as problem
+// details: when the client accepts application/problem+json, or when
+// PROBLEM_DETAILS is set.
+func wantsProblemDetails(r *http.Request) bool {
+	if on, _ := strconv.ParseBool(os.Getenv("PROBLEM_DETAILS")); on {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == problemContentType {
+			q, err := strconv.ParseFloat(params["q"], 64)
+			return err != nil || q > 0
+		}
+	}
+	return false
+}
+
+// newProblem builds the problem details for an error response.
+func newProblem(r *http.Request, message string, code int) problem {
+	p := problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: message,
+		Code:   "error",
+	}
+	if c, ok := problemCodes[code]; ok {
+		p.Code = c
+		p.Type = problemTypeBase() + strings.ReplaceAll(c, "_", "-")
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+	return p
+}
+
+// writeProblem writes an error as application/problem+json.
+func writeProblem(w http.ResponseWriter, r *http.Request, message string, code int) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(newProblem(r, message, code))
+}
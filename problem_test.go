@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminErrorsHandlerHonorsProblemDetails(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	before := len(recentErrors.Snapshot())
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/errors", nil)
+	r.Header.Set("Accept", problemContentType)
+	adminErrorsHandler(rec, r)
+
+	if got := rec.Header().Get("Content-Type"); got != problemContentType {
+		t.Fatalf("Content-Type = %q, want %q", got, problemContentType)
+	}
+	var p problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusForbidden || p.Code != "forbidden" || p.Instance != "/api/admin/errors" {
+		t.Errorf("problem = %+v", p)
+	}
+	if after := len(recentErrors.Snapshot()); after != before {
+		t.Errorf("admin endpoint failure was recorded in the errors buffer")
+	}
+}
+
+func TestWantsProblemDetails(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/json, application/problem+json;q=0.5", true},
+		{"application/problem+json;q=0", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", tt.accept)
+		if got := wantsProblemDetails(r); got != tt.want {
+			t.Errorf("wantsProblemDetails(Accept: %q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// promptVersions holds every system prompt the service can use, keyed by
+// version. Existing versions must not be edited once released; add a new
+// version instead so output changes can be correlated with the prompt.
+var promptVersions = map[string]string{
+	"v1": systemPrompt,
+	"v2": "You are an AI assistant that generates project ideas. Respond with a valid JSON array of objects and nothing else: no explanation, no markdown, no code fences. Each object must have exactly three string fields: \"name\" (a short project name), \"concept\" (one or two sentences describing the project) and \"features\" (a single string of comma-separated features). Generate exactly the number of ideas the user asks for, in this format: [{\"name\": \"Project Name\", \"concept\": \"Short description\", \"features\": \"Feature 1, Feature 2, Feature 3\"}].",
+}
+
+// fallbackPromptVersion is used when PROMPT_VERSION is unset or unknown.
+const fallbackPromptVersion = "v1"
+
+// defaultPromptVersion returns the server's pinned prompt version from
+// PROMPT_VERSION.
+func defaultPromptVersion() string {
+	v := os.Getenv("PROMPT_VERSION")
+	if v == "" {
+		return fallbackPromptVersion
+	}
+	if _, ok := promptVersions[v]; !ok {
+		log.Printf("unknown PROMPT_VERSION %q, using %s", v, fallbackPromptVersion)
+		return fallbackPromptVersion
+	}
+	return v
+}
+
+// sanitizePromptText prepares user-supplied text for inclusion in a prompt.
+// It drops control characters and backticks, collapses whitespace and cuts
+// the result to maxLen runes.
+func sanitizePromptText(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '`' || (unicode.IsControl(r) && !unicode.IsSpace(r)) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxLen {
+		s = strings.TrimSpace(string(runes[:maxLen]))
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// stopWords are ignored when extracting keywords for the relevance check.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true,
+	"this": true, "from": true, "into": true, "your": true, "app": true,
+	"are": true, "can": true, "who": true, "want": true, "help": true,
+}
+
+// relevanceThreshold returns the minimum relevance score from
+// RELEVANCE_THRESHOLD. The check is disabled when it is unset or invalid.
+func relevanceThreshold() (float64, bool) {
+	threshold, err := strconv.ParseFloat(os.Getenv("RELEVANCE_THRESHOLD"), 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		return 0, false
+	}
+	return threshold, true
+}
+
+// relevanceFilters reports whether off-topic ideas are dropped
+// (RELEVANCE_MODE=filter) rather than flagged, which is the default.
+func relevanceFilters() bool {
+	return os.Getenv("RELEVANCE_MODE") == "filter"
+}
+
+// keywordStems splits text into lowercase words and returns the set of
+// their stems, skipping short words and stop words. A stem is the first
+// five runes, which is enough to match plurals and simple inflections.
+func keywordStems(text string) map[string]bool {
+	stems := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if len([]rune(w)) < 3 || stopWords[w] {
+			continue
+		}
+		if r := []rune(w); len(r) > 5 {
+			w = string(r[:5])
+		}
+		stems[w] = true
+	}
+	return stems
+}
+
+// relevanceScore is the share of the request's keywords that appear in the
+// idea, between 0 and 1. A request without keywords scores every idea 1.
+func relevanceScore(idea Idea, req IdeaRequest) float64 {
+	keywords := keywordStems(req.Domain + " " + req.Description)
+	if len(keywords) == 0 {
+		return 1
+	}
+
+	ideaStems := keywordStems(idea.Name + " " + idea.Concept + " " + idea.Features)
+	matched := 0
+	for k := range keywords {
+		if ideaStems[k] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(keywords))
+}
+
+// checkRelevance scores idea against the request when the relevance check
+// is enabled and flags it when it falls below the threshold. It returns
+// false when the idea should be dropped.
+func checkRelevance(idea *Idea, req IdeaRequest) bool {
+	threshold, ok := relevanceThreshold()
+	if !ok {
+		return true
+	}
+
+	score := relevanceScore(*idea, req)
+	idea.Relevance = &score
+	if score >= threshold {
+		return true
+	}
+
+	if relevanceFilters() {
+		return false
+	}
+	idea.OffTopic = true
+	return true
+}
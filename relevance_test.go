@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestParseIdeasDropsModelSuppliedRelevance(t *testing.T) {
+	t.Setenv("RELEVANCE_THRESHOLD", "")
+	content := `[
+		{"name":"a","concept":"b","features":"c","relevance":0.99,"off_topic":true},
+		{"name":"a","concept":"b","features":"c"},
+		{"name":"a","concept":"b","features":"c"}
+	]`
+	ideas, err := parseIdeas(content, IdeaRequest{Domain: "gardening"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range ideas {
+		checkRelevance(&ideas[i], IdeaRequest{Domain: "gardening"})
+	}
+	if ideas[0].Relevance != nil || ideas[0].OffTopic {
+		t.Errorf("model-supplied relevance kept: relevance %v, off_topic %v", ideas[0].Relevance, ideas[0].OffTopic)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxSafetyScore is the top of the brand-safety scale; higher is safer.
+const maxSafetyScore = 100
+
+// SafetyScore is an idea's brand-safety score. It encodes as null when the
+// moderation pass failed, so clients can tell "unscored" from "unsafe".
+type SafetyScore struct {
+	Value float64
+	Valid bool
+}
+
+func (s SafetyScore) MarshalJSON() ([]byte, error) {
+	if !s.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(s.Value)
+}
+
+func (s *SafetyScore) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = SafetyScore{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &s.Value); err != nil {
+		return err
+	}
+	s.Valid = true
+	return nil
+}
+
+// safetyInstruction asks the moderation pass for one score per idea.
+var safetyInstruction = fmt.Sprintf("You are a brand-safety reviewer. The user sends a JSON array of project ideas. Rate each idea from 0 to %d for brand safety, where %d means nothing profane, offensive, adult, violent or otherwise risky for a mainstream brand to be associated with, and 0 means clearly unsafe. Respond with only a JSON object of the form {\"scores\": [...]} holding one number per idea, in the same order.", maxSafetyScore, maxSafetyScore)
+
+// scoreSafety attaches a brand-safety score to every idea using a single
+// moderation call for the whole batch. Nothing is dropped. When the call
+// fails, every score is left null.
+func scoreSafety(ideas []Idea, req IdeaRequest) {
+	scores, err := moderateIdeas(ideas)
+	if err != nil {
+		req.debug.Printf("moderation failed: %v", err)
+		metrics.Incr("moderation.failures")
+	}
+	for i := range ideas {
+		score := SafetyScore{}
+		if err == nil {
+			score = SafetyScore{Value: math.Max(0, math.Min(maxSafetyScore, scores[i])), Valid: true}
+		}
+		ideas[i].SafetyScore = &score
+	}
+}
+
+// moderateIdeas returns one brand-safety score per idea.
+func moderateIdeas(ideas []Idea) ([]float64, error) {
+	if len(ideas) == 0 {
+		return nil, nil
+	}
+
+	type moderated struct {
+		Name     string `json:"name"`
+		Concept  string `json:"concept"`
+		Features string `json:"features"`
+	}
+	batch := make([]moderated, len(ideas))
+	for i, idea := range ideas {
+		batch[i] = moderated{Name: idea.Name, Concept: idea.Concept, Features: idea.Features}
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	groqReq := newGroqRequest([]GroqMessage{
+		{Role: "system", Content: safetyInstruction},
+		{Role: "user", Content: string(data)},
+	})
+	groqReq.Temperature = 0
+	groqReq.ResponseFormat = &GroqResponseFormat{Type: "json_object"}
+	if mockEnabled() {
+		groqReq.mockContent = `{"scores": [` + strings.TrimSuffix(strings.Repeat(strconv.Itoa(maxSafetyScore)+",", len(ideas)), ",") + `]}`
+	}
+
+	content, err := callGroq(groqReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation scores: %v", err)
+	}
+	if len(result.Scores) != len(ideas) {
+		return nil, fmt.Errorf("expected %d moderation scores, got %d", len(ideas), len(result.Scores))
+	}
+	return result.Scores, nil
+}
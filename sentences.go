@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxConceptSentences caps the concept_sentences option.
+const maxConceptSentences = 10
+
+// errConceptSentences reports a concept with the wrong number of sentences
+// when CONCEPT_SENTENCES_STRICT is set.
+var errConceptSentences = errors.New("invalid idea format: concept has the wrong number of sentences")
+
+// conceptSentencesStrict reports whether a concept with the wrong sentence
+// count fails validation, so the generation is re-rolled, instead of being
+// truncated.
+func conceptSentencesStrict() bool {
+	strict, _ := strconv.ParseBool(os.Getenv("CONCEPT_SENTENCES_STRICT"))
+	return strict
+}
+
+// abbreviations end in a period without ending a sentence.
+var abbreviations = map[string]bool{
+	"e.g.": true, "i.e.": true, "etc.": true, "vs.": true, "approx.": true,
+	"mr.": true, "mrs.": true, "ms.": true, "dr.": true, "prof.": true,
+	"inc.": true, "ltd.": true, "co.": true, "corp.": true, "st.": true,
+	"no.": true, "a.m.": true, "p.m.": true, "u.s.": true, "u.k.": true,
+}
+
+// sentenceClosers may follow terminal punctuation within the same sentence.
+const sentenceClosers = ".!?\"')]”’"
+
+// splitSentences splits text on terminal punctuation followed by a space.
+// Periods in abbreviations, initials, decimals and domains do not end a
+// sentence, except at the very end of the text.
+func splitSentences(text string) []string {
+	runes := []rune(strings.TrimSpace(text))
+	var sentences []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune(sentenceClosers, runes[end]) {
+			end++
+		}
+		if end < len(runes) {
+			if !unicode.IsSpace(runes[end]) || (r == '.' && end == i+1 && isAbbreviation(runes[start:end])) {
+				i = end - 1
+				continue
+			}
+		}
+		sentences = append(sentences, strings.TrimSpace(string(runes[start:end])))
+		start = end
+		i = end - 1
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// isAbbreviation reports whether the last word of s, which ends in a
+// period, is an abbreviation or an initial rather than a sentence end.
+func isAbbreviation(s []rune) bool {
+	words := strings.Fields(string(s))
+	if len(words) == 0 {
+		return false
+	}
+	word := strings.ToLower(strings.TrimLeft(words[len(words)-1], "(\"'“‘"))
+	if abbreviations[word] {
+		return true
+	}
+	// A single capital letter, as in "J. Smith".
+	letters := []rune(strings.TrimSuffix(words[len(words)-1], "."))
+	return len(letters) == 1 && unicode.IsUpper(letters[0])
+}
+
+// fitConceptSentences checks idea's concept against the requested sentence
+// count, truncating extra sentences unless CONCEPT_SENTENCES_STRICT is set.
+func fitConceptSentences(idea *Idea, n int) error {
+	sentences := splitSentences(idea.Concept)
+	if len(sentences) == n {
+		return nil
+	}
+	if conceptSentencesStrict() {
+		return errConceptSentences
+	}
+	if len(sentences) > n {
+		idea.Concept = strings.Join(sentences[:n], " ")
+	}
+	return nil
+}
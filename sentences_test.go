@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"No punctuation", []string{"No punctuation"}},
+		{"One. Two! Three?", []string{"One.", "Two!", "Three?"}},
+		{"Use tools, e.g. rakes. Then rest.", []string{"Use tools, e.g. rakes.", "Then rest."}},
+		{"Dr. Smith and J. Doe built it. It works.", []string{"Dr. Smith and J. Doe built it.", "It works."}},
+		{"Costs $3.50 a month at example.com. Cheap.", []string{"Costs $3.50 a month at example.com.", "Cheap."}},
+		{"Sold in the U.S. and the U.K. since 2020.", []string{"Sold in the U.S. and the U.K. since 2020."}},
+		{"It ends with etc.", []string{"It ends with etc."}},
+		{`He said "wow." Then left.`, []string{`He said "wow."`, "Then left."}},
+		{"Wait... really?! Yes.", []string{"Wait...", "really?!", "Yes."}},
+	}
+	for _, tt := range tests {
+		if got := splitSentences(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitSentences(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFitConceptSentences(t *testing.T) {
+	idea := Idea{Concept: "One. Two. Three."}
+	if err := fitConceptSentences(&idea, 2); err != nil || idea.Concept != "One. Two." {
+		t.Errorf("truncated concept = %q, err %v; want %q", idea.Concept, err, "One. Two.")
+	}
+
+	t.Setenv("CONCEPT_SENTENCES_STRICT", "true")
+	idea = Idea{Concept: "Only one."}
+	if err := fitConceptSentences(&idea, 2); err != errConceptSentences {
+		t.Errorf("strict mismatch err = %v, want errConceptSentences", err)
+	}
+}
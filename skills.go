@@ -0,0 +1,58 @@
+package main
+
+import "strings"
+
+// maxSkills caps the skills listed per idea.
+const maxSkills = 8
+
+// canonicalSkills gives the conventional spelling of common skills, keyed
+// by normalizeFeature.
+var canonicalSkills = map[string]string{
+	"react": "React", "react.js": "React", "reactjs": "React",
+	"javascript": "JavaScript", "js": "JavaScript", "typescript": "TypeScript", "ts": "TypeScript",
+	"node": "Node.js", "node.js": "Node.js", "nodejs": "Node.js",
+	"python": "Python", "go": "Go", "golang": "Go", "java": "Java", "rust": "Rust",
+	"swift": "Swift", "kotlin": "Kotlin", "ios": "iOS", "android": "Android",
+	"sql": "SQL", "postgresql": "PostgreSQL", "postgres": "PostgreSQL", "graphql": "GraphQL",
+	"ml": "ML", "machine learning": "ML", "ai": "AI", "nlp": "NLP",
+	"aws": "AWS", "gcp": "GCP", "azure": "Azure", "docker": "Docker", "kubernetes": "Kubernetes",
+	"devops": "DevOps", "ui": "UI", "ux": "UX", "ui/ux": "UI/UX", "seo": "SEO",
+	"html": "HTML", "html5": "HTML5", "css": "CSS", "css3": "CSS3", "html/css": "HTML/CSS",
+	"figma": "Figma", "flutter": "Flutter", "stripe": "Stripe", "firebase": "Firebase",
+	"vue": "Vue", "vue.js": "Vue", "angular": "Angular", "next.js": "Next.js", "nextjs": "Next.js",
+	"tailwind": "Tailwind CSS", "tailwind css": "Tailwind CSS", "react native": "React Native",
+	"mongodb": "MongoDB", "mysql": "MySQL", "redis": "Redis", "php": "PHP", "c#": "C#", "c++": "C++",
+	"tensorflow": "TensorFlow", "pytorch": "PyTorch", "llm": "LLM", "llms": "LLMs",
+	"ci/cd": "CI/CD", "rest": "REST", "api": "API", "apis": "APIs",
+}
+
+// normalizeSkill returns the display form of a skill: its canonical
+// spelling when known, and otherwise the model's casing, so acronyms such
+// as "REST API" survive.
+func normalizeSkill(skill string) string {
+	skill = strings.Join(strings.Fields(strings.Trim(strings.TrimSpace(skill), ".;:!\"'")), " ")
+	if canonical, ok := canonicalSkills[normalizeFeature(skill)]; ok {
+		return canonical
+	}
+	return skill
+}
+
+// cleanSkills normalizes the casing of skills, drops empty and repeated
+// ones and keeps at most maxSkills.
+func cleanSkills(raw []string) []string {
+	seen := make(map[string]bool)
+	skills := []string{}
+	for _, s := range raw {
+		s = normalizeSkill(s)
+		key := normalizeFeature(s)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		skills = append(skills, s)
+		if len(skills) == maxSkills {
+			break
+		}
+	}
+	return skills
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeSkill(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"react", "React"},
+		{"REACT.JS", "React"},
+		{"machine learning", "ML"},
+		{"Figma", "Figma"},
+		{"flutter", "Flutter"},
+		{"Stripe", "Stripe"},
+		{"HTML5", "HTML5"},
+		{"html/css", "HTML/CSS"},
+		{"GraphQL", "GraphQL"},
+		{"GDPR", "GDPR"},
+		{"design", "design"},
+		{"Product Management", "Product Management"},
+		{"REST API", "REST API"},
+		{"AWS S3", "AWS S3"},
+		{"UI UX", "UI UX"},
+		{"  copywriting. ", "copywriting"},
+	}
+	for _, tt := range tests {
+		if got := normalizeSkill(tt.in); got != tt.want {
+			t.Errorf("normalizeSkill(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCleanSkills(t *testing.T) {
+	got := cleanSkills([]string{"react", "React", "", "ML", "machine learning", "design", "Go", "Rust", "SQL", "AWS", "Docker", "Figma"})
+	want := []string{"React", "ML", "design", "Go", "Rust", "SQL", "AWS", "Docker"}
+	if !slices.Equal(got, want) {
+		t.Errorf("cleanSkills = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// streamErrorTrailer is set when a streamed array fails after the response
+// has started and the status can no longer change.
+const streamErrorTrailer = "X-Stream-Error"
+
+// streamIdeasHandler serves ?stream=json. It streams the upstream
+// completion and writes a well-formed JSON array one idea at a time,
+// flushing after each object.
+//
+// The opening '[' is held back until the first idea parses, so a failure
+// before that still gets a normal error response. A failure after that
+// leaves the array unterminated, so the client can never mistake a partial
+// body for a complete one, and sets the X-Stream-Error trailer. When
+// STREAM_HEARTBEAT_MS is set, newlines are sent while the stream is idle.
+// The disclaimer, when enabled, is sent in the X-Disclaimer header since a
+// bare array has no meta.
+func streamIdeasHandler(w http.ResponseWriter, r *http.Request, req IdeaRequest, fields []string) {
+	if req.wantsEnvelope() {
+		writeError(w, r, "stream=json cannot be combined with options that return meta", http.StatusBadRequest)
+		return
+	}
+	if req.RankBy != "" || req.MergeCommonFeatures || req.VerifyDeterminism || req.IncludeSafetyScore {
+		writeError(w, r, "stream=json cannot be combined with options that need every idea first", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// JSON mode needs an object, which would defeat incremental parsing.
+	req.strictness = min(strictness.Level(activeModel(defaultModel)), strictnessFirm)
+	groqReq := newGenerationRequest(req)
+	req.debug.Printf("streaming upstream request: model=%s strictness=%d messages=%+v", groqReq.Model, req.strictness, groqReq.Messages)
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		if err := streamGroq(r.Context(), groqReq, pw); err != nil {
+			pw.CloseWithError(&upstreamStreamError{err: err})
+			return
+		}
+		pw.Close()
+	}()
+
+	w.Header().Set("Trailer", streamErrorTrailer)
+	if d := disclaimer(); d != "" {
+		w.Header().Set(disclaimerHeader, strings.Join(strings.Fields(d), " "))
+	}
+	out := &streamWriter{w: w, flusher: flusher}
+	stopHeartbeat := out.heartbeat(streamHeartbeatInterval())
+	defer stopHeartbeat()
+
+	parsed, written := 0, 0
+	fail := func(err error) {
+		stopHeartbeat()
+		req.debug.Printf("stream failed after %d parsed ideas: %v", parsed, err)
+		// Only the model's output counts towards strictness; an upstream
+		// outage or a client disconnect says nothing about parseability.
+		var upstream *upstreamStreamError
+		if errors.As(err, &upstream) {
+			err = upstream.err
+		} else {
+			strictness.Record(activeModel(defaultModel), false)
+			metrics.Incr("parse_failures")
+		}
+		metrics.Incr("requests", "status:error")
+		if !out.started {
+			writeError(w, r, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("stream failed after %d ideas: %v", written, err)
+		recentErrors.Record(r, http.StatusInternalServerError, err.Error())
+		w.Header().Set(streamErrorTrailer, err.Error())
+	}
+
+	dec := json.NewDecoder(pr)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		if err == nil {
+			err = fmt.Errorf("failed to parse JSON: expected an array")
+		}
+		fail(err)
+		return
+	}
+
+	for dec.More() {
+		var idea Idea
+		if err := dec.Decode(&idea); err != nil {
+			fail(fmt.Errorf("failed to parse JSON: %w", err))
+			return
+		}
+		if err := validateIdea(&idea, req); err != nil {
+			fail(err)
+			return
+		}
+		parsed++
+		if parsed > ideaCount {
+			// Extras within the model's tolerance are dropped.
+			if parsed > ideaCount+countTolerance(activeModel(groqReq.Model)) {
+				fail(checkIdeaCount(parsed, activeModel(groqReq.Model)))
+				return
+			}
+			continue
+		}
+		if mentionsExcluded(idea, req.ExcludeKeywords) || !checkRelevance(&idea, req) {
+			continue
+		}
+
+		data, err := marshalStreamedIdea(idea, fields)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		if written == 0 {
+			out.write("[")
+		} else {
+			out.write(",")
+		}
+		out.write(string(data))
+		written++
+	}
+
+	if err := checkIdeaCount(parsed, activeModel(groqReq.Model)); err != nil {
+		fail(err)
+		return
+	}
+
+	stopHeartbeat()
+	req.debug.Printf("streamed %d of %d parsed ideas", written, parsed)
+	strictness.Record(activeModel(defaultModel), true)
+	metrics.Incr("requests", "status:ok")
+	if written == 0 {
+		// Every idea was filtered out, so the array was never opened.
+		out.write("[")
+	}
+	out.write("]")
+}
+
+// upstreamStreamError carries a streamGroq failure through the pipe, so the
+// handler can tell it apart from a decode or validation failure.
+type upstreamStreamError struct {
+	err error
+}
+
+func (e *upstreamStreamError) Error() string { return e.err.Error() }
+
+func (e *upstreamStreamError) Unwrap() error { return e.err }
+
+// streamHeartbeatInterval is how often an idle stream sends a heartbeat,
+// configured in milliseconds by STREAM_HEARTBEAT_MS. Zero disables it.
+func streamHeartbeatInterval() time.Duration {
+	ms, err := strconv.Atoi(os.Getenv("STREAM_HEARTBEAT_MS"))
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// streamWriter serializes writes to a streamed response so heartbeats can
+// be sent while the handler waits on the upstream.
+type streamWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	// started is set once anything, heartbeats included, has been written
+	// and the status can no longer change.
+	started bool
+}
+
+func (s *streamWriter) write(chunk string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		s.w.Header().Set("Content-Type", "application/json")
+		s.started = true
+	}
+	io.WriteString(s.w, chunk)
+	s.flusher.Flush()
+}
+
+// heartbeat writes a newline every interval until the returned stop func is
+// called, so proxies that drop idle connections keep the stream open.
+// Whitespace is valid anywhere between JSON tokens, so clients parsing the
+// array are unaffected. A heartbeat commits the 200 status, so a failure
+// after one is reported in the trailer like any mid-stream failure.
+func (s *streamWriter) heartbeat(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.write("\n")
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+func marshalStreamedIdea(idea Idea, fields []string) ([]byte, error) {
+	if len(fields) == 0 {
+		return json.Marshal(idea)
+	}
+	sparse, err := filterIdeaFields([]Idea{idea}, fields)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sparse[0])
+}
+
+// groqStreamChunk is one server-sent event of a streamed completion.
+type groqStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// streamGroq sends groqReq with streaming enabled and writes the content
+// deltas to out as they arrive. Cancelling ctx, as happens when the client
+// disconnects, aborts the upstream completion. A model the upstream reports
+// as gone is retried on the successor, which is safe because nothing has
+// been written to out by then.
+func streamGroq(ctx context.Context, groqReq GroqRequest, out io.Writer) error {
+	err := streamGroqOnce(ctx, groqReq, out)
+	if err != nil {
+		_, err = retryOnSuccessor(groqReq, err, func(groqReq GroqRequest) (string, error) {
+			return "", streamGroqOnce(ctx, groqReq, out)
+		})
+	}
+	return err
+}
+
+// streamGroqOnce makes a single streamed chat completion call.
+func streamGroqOnce(ctx context.Context, groqReq GroqRequest, out io.Writer) error {
+	groqReq.Stream = true
+
+	resp, err := doGroq(ctx, groqReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var result map[string]interface{}
+		if json.NewDecoder(resp.Body).Decode(&result) == nil {
+			if err := upstreamModelError(groqReq.Model, result); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("upstream returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk groqStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("unexpected stream chunk: %v", err)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if _, err := io.WriteString(out, chunk.Choices[0].Delta.Content); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamCarriesDisclaimerHeader(t *testing.T) {
+	t.Setenv("GROQ_MOCK", "true")
+	t.Setenv("DISCLAIMER_ENABLED", "true")
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/generate-ideas?stream=json", strings.NewReader(`{"domain":"gardening"}`))
+	generateIdeasHandler(rec, r)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get(disclaimerHeader); got != defaultDisclaimer {
+		t.Errorf("%s = %q, want %q", disclaimerHeader, got, defaultDisclaimer)
+	}
+}
+
+func TestStreamInfersMissingDomain(t *testing.T) {
+	t.Setenv("GROQ_MOCK", "true")
+	t.Setenv("ENABLE_DOMAIN_INFERENCE", "true")
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/generate-ideas?stream=json", strings.NewReader(`{"description":"help people water plants"}`))
+	generateIdeasHandler(rec, r)
+
+	// In mock mode the inferred domain is the canned reply "mock".
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "A canned idea for mock") {
+		t.Errorf("streamed ideas were not generated for the inferred domain: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestStreamGroqStopsWhenContextIsCancelled(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "test-key")
+	t.Setenv("GROQ_MOCK", "")
+	orig := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = orig })
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- streamGroq(ctx, GroqRequest{Model: defaultModel}, io.Discard) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("streamGroq error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("streamGroq kept running after the context was cancelled")
+	}
+}
+
+func TestStreamUpstreamFailureLeavesStrictnessAlone(t *testing.T) {
+	t.Setenv("ADAPTIVE_STRICTNESS", "true")
+	t.Setenv("PARSE_STRICTNESS_WINDOW", "5")
+	t.Setenv("GROQ_API_KEY", "test-key")
+	t.Setenv("GROQ_MOCK", "")
+	orig := strictness
+	strictness = newStrictnessControllerFromEnv()
+	t.Cleanup(func() { strictness = orig })
+	origTransport := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = origTransport })
+	http.DefaultTransport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{}`)), Header: http.Header{}}, nil
+	})
+
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/generate-ideas?stream=json", strings.NewReader(`{"domain":"gardening"}`))
+		generateIdeasHandler(rec, r)
+		if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "upstream returned status 503") {
+			t.Fatalf("got %d %s, want the upstream status reported", rec.Code, rec.Body)
+		}
+	}
+	if got := strictness.Level(defaultModel); got != strictnessNormal {
+		t.Errorf("strictness = %d after upstream outages, want %d", got, strictnessNormal)
+	}
+}
+
+func TestStreamGroqRetriesRetiredModelOnSuccessor(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "test-key")
+	t.Setenv("GROQ_MOCK", "")
+	t.Setenv("MODEL_SUCCESSOR", "successor-model")
+	t.Cleanup(func() { retiredModels.Delete(defaultModel) })
+	orig := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = orig })
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(data), defaultModel) {
+			body := `{"error":{"code":"model_decommissioned","message":"The model has been decommissioned"}}`
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+		}
+		body := "data: {\"choices\":[{\"delta\":{\"content\":\"[]\"}}]}\n\ndata: [DONE]\n\n"
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+
+	var out strings.Builder
+	if err := streamGroq(context.Background(), GroqRequest{Model: defaultModel}, &out); err != nil {
+		t.Fatalf("streamGroq: %v", err)
+	}
+	if out.String() != "[]" {
+		t.Errorf("streamed %q, want the successor's completion", out.String())
+	}
+	if got := activeModel(defaultModel); got != "successor-model" {
+		t.Errorf("activeModel = %q, want the successor after the stream saw the model gone", got)
+	}
+}
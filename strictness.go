@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Prompt strictness levels, escalated when parse failures spike.
+const (
+	strictnessNormal = iota
+	// strictnessFirm adds a stronger JSON-only instruction.
+	strictnessFirm
+	// strictnessJSONMode also switches on Groq's JSON mode, which requires
+	// the model to return an object, so the ideas are requested inside the
+	// envelope.
+	strictnessJSONMode
+)
+
+// strictnessController tracks recent parse outcomes per model and raises
+// or lowers prompt strictness accordingly. A nil controller is disabled and
+// always reports strictnessNormal.
+type strictnessController struct {
+	mu         sync.Mutex
+	window     int
+	minSamples int
+	threshold  float64
+	models     map[string]*parseOutcomes
+}
+
+// parseOutcomes is a ring buffer of recent parse results for one model.
+type parseOutcomes struct {
+	results []bool
+	next    int
+	filled  int
+	level   int
+}
+
+// strictness is the process-wide controller, set up in main.
+var strictness *strictnessController
+
+// newStrictnessControllerFromEnv returns a controller when
+// ADAPTIVE_STRICTNESS is set. PARSE_STRICTNESS_WINDOW is the number of
+// recent requests considered and PARSE_FAILURE_THRESHOLD the failure rate
+// above which strictness is escalated.
+func newStrictnessControllerFromEnv() *strictnessController {
+	if enabled, _ := strconv.ParseBool(os.Getenv("ADAPTIVE_STRICTNESS")); !enabled {
+		return nil
+	}
+
+	window := 20
+	if v, err := strconv.Atoi(os.Getenv("PARSE_STRICTNESS_WINDOW")); err == nil && v > 0 {
+		window = v
+	}
+	threshold := 0.3
+	if v, err := strconv.ParseFloat(os.Getenv("PARSE_FAILURE_THRESHOLD"), 64); err == nil && v > 0 && v < 1 {
+		threshold = v
+	}
+
+	minSamples := 5
+	if minSamples > window {
+		minSamples = window
+	}
+
+	return &strictnessController{
+		window:     window,
+		minSamples: minSamples,
+		threshold:  threshold,
+		models:     make(map[string]*parseOutcomes),
+	}
+}
+
+// Level returns the current strictness level for model.
+func (c *strictnessController) Level(model string) int {
+	if c == nil {
+		return strictnessNormal
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if o, ok := c.models[model]; ok {
+		return o.level
+	}
+	return strictnessNormal
+}
+
+// Levels returns the current strictness level of every model with recorded
+// parse outcomes.
+func (c *strictnessController) Levels() map[string]int {
+	levels := make(map[string]int)
+	if c == nil {
+		return levels
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for model, o := range c.models {
+		levels[model] = o.level
+	}
+	return levels
+}
+
+// Record adds a parse outcome for model and adjusts its level. The window
+// is cleared after every change so the new level is judged on its own
+// results.
+func (c *strictnessController) Record(model string, ok bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, found := c.models[model]
+	if !found {
+		o = &parseOutcomes{results: make([]bool, c.window)}
+		c.models[model] = o
+	}
+
+	o.results[o.next] = ok
+	o.next = (o.next + 1) % len(o.results)
+	if o.filled < len(o.results) {
+		o.filled++
+	}
+	if o.filled < c.minSamples {
+		return
+	}
+
+	failures := 0
+	for i := 0; i < o.filled; i++ {
+		if !o.results[i] {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(o.filled)
+
+	switch {
+	case rate > c.threshold && o.level < strictnessJSONMode:
+		o.level++
+	case rate < c.threshold/2 && o.level > strictnessNormal:
+		o.level--
+	default:
+		return
+	}
+	o.next, o.filled = 0, 0
+	metrics.Gauge("prompt.strictness", float64(o.level), "model:"+model)
+}
+
+// adminStrictnessHandler serves GET /api/admin/strictness, the current
+// prompt strictness level per model, to callers with the admin token.
+func adminStrictnessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorBody(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isAdmin(r) {
+		writeErrorBody(w, r, "admin access required", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Enabled bool           `json:"enabled"`
+		Levels  map[string]int `json:"levels"`
+	}{Enabled: strictness != nil, Levels: strictness.Levels()})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminStrictnessHandler(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	t.Setenv("ADAPTIVE_STRICTNESS", "true")
+	t.Setenv("PARSE_STRICTNESS_WINDOW", "5")
+	orig := strictness
+	strictness = newStrictnessControllerFromEnv()
+	t.Cleanup(func() { strictness = orig })
+	for i := 0; i < 5; i++ {
+		strictness.Record(defaultModel, false)
+	}
+
+	rec := httptest.NewRecorder()
+	adminStrictnessHandler(rec, httptest.NewRequest(http.MethodGet, "/api/admin/strictness", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status without token = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/strictness", nil)
+	r.Header.Set(adminTokenHeader, "secret")
+	adminStrictnessHandler(rec, r)
+	var got struct {
+		Enabled bool           `json:"enabled"`
+		Levels  map[string]int `json:"levels"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Enabled || got.Levels[defaultModel] != strictnessFirm {
+		t.Errorf("strictness stats = %+v, want %s at level %d", got, defaultModel, strictnessFirm)
+	}
+}
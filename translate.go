@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// supportedLanguages maps accepted language codes to the names used in
+// prompts.
+var supportedLanguages = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"zh": "Chinese",
+}
+
+// maxTranslateIdeas caps how many ideas one translation request can carry.
+const maxTranslateIdeas = 10
+
+// TranslateRequest is the body of POST /api/translate: an IdeaResponse plus
+// the language to translate it into.
+type TranslateRequest struct {
+	Ideas          []Idea `json:"ideas"`
+	TargetLanguage string `json:"target_language"`
+	// TranslateNames also translates idea names, which are kept as-is by
+	// default because they usually act as product names.
+	TranslateNames bool `json:"translate_names"`
+}
+
+// TranslateResponse returns the translated ideas. Ideas that could not be
+// translated are returned unchanged and listed in Meta.Failed.
+type TranslateResponse struct {
+	Ideas []Idea        `json:"ideas"`
+	Meta  TranslateMeta `json:"meta"`
+}
+
+// TranslateMeta reports the language used and any per-idea failures.
+type TranslateMeta struct {
+	TargetLanguage string             `json:"target_language"`
+	Failed         []TranslateFailure `json:"failed,omitempty"`
+}
+
+// TranslateFailure identifies an idea, by position, that was not translated.
+type TranslateFailure struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// translateHandler serves POST /api/translate.
+func translateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TranslateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.TargetLanguage = strings.ToLower(strings.TrimSpace(req.TargetLanguage))
+	language, ok := supportedLanguages[req.TargetLanguage]
+	if !ok {
+		writeError(w, r, fmt.Sprintf("unsupported target_language %q", req.TargetLanguage), http.StatusBadRequest)
+		return
+	}
+	if len(req.Ideas) == 0 {
+		writeError(w, r, "ideas must not be empty", http.StatusBadRequest)
+		return
+	}
+	if len(req.Ideas) > maxTranslateIdeas {
+		writeError(w, r, fmt.Sprintf("at most %d ideas can be translated at once", maxTranslateIdeas), http.StatusBadRequest)
+		return
+	}
+
+	for i, idea := range req.Ideas {
+		if idea.Name == "" || idea.Concept == "" || idea.Features == "" {
+			writeError(w, r, fmt.Sprintf("idea %d: all fields must be non-empty", i+1), http.StatusBadRequest)
+			return
+		}
+	}
+
+	response := TranslateResponse{
+		Ideas: make([]Idea, len(req.Ideas)),
+		Meta:  TranslateMeta{TargetLanguage: req.TargetLanguage},
+	}
+	errs := make([]error, len(req.Ideas))
+
+	var wg sync.WaitGroup
+	for i, idea := range req.Ideas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			translated, err := translateIdea(idea, language, req.TranslateNames)
+			if err != nil {
+				translated = idea
+			}
+			response.Ideas[i], errs[i] = translated, err
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			response.Meta.Failed = append(response.Meta.Failed, TranslateFailure{Index: i, Error: err.Error()})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// translateIdea translates the text of one idea into language. The model
+// output is decoded over a copy of the original, so fields it drops keep
+// their original value, and fields that are not prose are restored.
+func translateIdea(idea Idea, language string, translateNames bool) (Idea, error) {
+	data, err := json.Marshal(idea)
+	if err != nil {
+		return Idea{}, err
+	}
+
+	instruction := fmt.Sprintf("Translate every human-readable string value in the user's JSON object into %s. Keep all keys, the structure and any numbers unchanged.", language)
+	if !translateNames {
+		instruction += " Do not translate the 'name' field."
+	}
+	instruction += " Respond with the translated JSON object only."
+
+	groqReq := newGroqRequest([]GroqMessage{
+		{Role: "system", Content: instruction},
+		{Role: "user", Content: string(data)},
+	})
+	groqReq.Temperature = 0
+	groqReq.ResponseFormat = &GroqResponseFormat{Type: "json_object"}
+	if mockEnabled() {
+		groqReq.mockContent = string(data)
+	}
+
+	content, err := callGroq(groqReq)
+	if err != nil {
+		return Idea{}, err
+	}
+
+	// Decode over a deep copy: json.Unmarshal reuses slices and pointers,
+	// and the caller falls back to the original idea on failure.
+	translated := cloneIdea(idea)
+	if err := json.Unmarshal([]byte(content), &translated); err != nil {
+		return Idea{}, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	if translated.Name == "" || translated.Concept == "" || translated.Features == "" {
+		return Idea{}, fmt.Errorf("invalid idea format: all fields must be non-empty")
+	}
+
+	if !translateNames {
+		translated.Name = idea.Name
+	}
+	translated.Effort = idea.Effort
+	translated.Monetization = idea.Monetization
+	for i := range translated.Roadmap {
+		if i < len(idea.Roadmap) {
+			translated.Roadmap[i].Name = idea.Roadmap[i].Name
+		}
+	}
+	translated.Score = idea.Score
+	translated.Relevance = idea.Relevance
+	translated.SafetyScore = idea.SafetyScore
+	translated.OffTopic = idea.OffTopic
+	return translated, nil
+}
+
+// cloneIdea returns a copy of idea that shares no slices or pointers with it.
+func cloneIdea(idea Idea) Idea {
+	c := idea
+	c.FeatureDetails = slices.Clone(idea.FeatureDetails)
+	c.Competitors = slices.Clone(idea.Competitors)
+	c.Dependencies = slices.Clone(idea.Dependencies)
+	c.Risks = slices.Clone(idea.Risks)
+	c.Accessibility = slices.Clone(idea.Accessibility)
+	c.Skills = slices.Clone(idea.Skills)
+	c.Roadmap = slices.Clone(idea.Roadmap)
+	for i := range c.Roadmap {
+		c.Roadmap[i].Goals = slices.Clone(idea.Roadmap[i].Goals)
+	}
+	if idea.SWOT != nil {
+		c.SWOT = &SWOT{
+			Strengths:     slices.Clone(idea.SWOT.Strengths),
+			Weaknesses:    slices.Clone(idea.SWOT.Weaknesses),
+			Opportunities: slices.Clone(idea.SWOT.Opportunities),
+			Threats:       slices.Clone(idea.SWOT.Threats),
+		}
+	}
+	c.Score = clonePtr(idea.Score)
+	c.Relevance = clonePtr(idea.Relevance)
+	c.SafetyScore = clonePtr(idea.SafetyScore)
+	return c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc stubs the upstream for tests.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// stubUpstream makes every upstream call return content as the completion.
+// The returned string holds the body of the latest upstream request.
+func stubUpstream(t *testing.T, content string) *string {
+	t.Helper()
+	t.Setenv("GROQ_API_KEY", "test-key")
+	t.Setenv("GROQ_MOCK", "")
+	orig := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = orig })
+	var last string
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(r.Body)
+		last = string(data)
+		body := `{"choices":[{"message":{"content":` + strconvQuote(content) + `}}]}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+	return &last
+}
+
+func testIdea() Idea {
+	score := 7.0
+	return Idea{
+		Name:        "Plant Pal",
+		Concept:     "Reminds you to water plants.",
+		Features:    "Reminders, Care tips",
+		Competitors: []string{"Planta"},
+		Risks:       []string{"Low retention"},
+		Skills:      []string{"Go"},
+		SWOT:        &SWOT{Strengths: []string{"Simple"}},
+		Roadmap:     []RoadmapPhase{{Name: "MVP", Goals: []string{"Reminders"}}},
+		Score:       &score,
+	}
+}
+
+func TestTranslateIdeaLeavesOriginalOnFailure(t *testing.T) {
+	// Valid JSON that fails the non-empty check after overwriting lists.
+	stubUpstream(t, `{"name":"","competitors":["X"],"risks":["Y"],"skills":["Z"],"swot":{"strengths":["S"]},"roadmap":[{"name":"MVP","goals":["G"]}],"score":1}`)
+
+	idea := testIdea()
+	if _, err := translateIdea(idea, "German", false); err == nil {
+		t.Fatal("translateIdea succeeded, want an error")
+	}
+
+	want := testIdea()
+	if idea.Competitors[0] != want.Competitors[0] || idea.Risks[0] != want.Risks[0] ||
+		idea.Skills[0] != want.Skills[0] || idea.SWOT.Strengths[0] != want.SWOT.Strengths[0] ||
+		idea.Roadmap[0].Goals[0] != want.Roadmap[0].Goals[0] || *idea.Score != *want.Score {
+		t.Errorf("original idea was modified: %+v", idea)
+	}
+}
+
+// strconvQuote quotes s as a JSON string.
+func strconvQuote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// uploadExtensions are the file types accepted for generating ideas from a
+// document. Binary formats such as PDF must be converted to text first.
+var uploadExtensions = map[string]bool{
+	".txt":      true,
+	".text":     true,
+	".md":       true,
+	".markdown": true,
+}
+
+// uploadContentTypes are the accepted part content types. Some clients send
+// application/octet-stream for any file, so the extension decides then.
+var uploadContentTypes = map[string]bool{
+	"text/plain":               true,
+	"text/markdown":            true,
+	"text/x-markdown":          true,
+	"application/octet-stream": true,
+	"":                         true,
+}
+
+// charsPerToken is a rough estimate used to turn a token budget into a
+// character limit for uploaded text.
+const charsPerToken = 4
+
+// maxUploadBytes returns MAX_UPLOAD_BYTES, default 1 MiB.
+func maxUploadBytes() int64 {
+	if v, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_BYTES"), 10, 64); err == nil && v > 0 {
+		return v
+	}
+	return 1 << 20
+}
+
+// fileContextChars returns how much of an uploaded document is used as
+// description context, from FILE_CONTEXT_MAX_TOKENS (default 2000 tokens).
+func fileContextChars() int {
+	tokens := 2000
+	if v, err := strconv.Atoi(os.Getenv("FILE_CONTEXT_MAX_TOKENS")); err == nil && v > 0 {
+		tokens = v
+	}
+	return tokens * charsPerToken
+}
+
+// generateIdeasFromFileHandler serves POST /api/generate-ideas/from-file. It
+// takes a multipart upload with a 'file' part holding a text or markdown
+// document and optional 'domain' and 'description' fields. The document
+// text, truncated to the token budget, becomes the description context.
+func generateIdeasFromFileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := maxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, limit+64*1024)
+	if err := r.ParseMultipartForm(limit); err != nil {
+		writeError(w, r, fmt.Sprintf("invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, r, "missing 'file' part", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > limit {
+		writeError(w, r, fmt.Sprintf("file too large: limit is %d bytes", limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	contentType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	if !uploadExtensions[ext] || !uploadContentTypes[contentType] {
+		writeError(w, r, fmt.Sprintf("unsupported file type %q: upload plain text or markdown (.txt, .md); extract text from PDFs before uploading", header.Filename), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if int64(len(data)) > limit {
+		writeError(w, r, fmt.Sprintf("file too large: limit is %d bytes", limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		writeError(w, r, "file is not valid UTF-8 text", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		writeError(w, r, "file is empty", http.StatusBadRequest)
+		return
+	}
+	if runes := []rune(text); len(runes) > fileContextChars() {
+		text = string(runes[:fileContextChars()])
+	}
+
+	description := text
+	if extra := strings.TrimSpace(r.FormValue("description")); extra != "" {
+		description = extra + "\n\nDocument:\n" + text
+	}
+
+	serveIdeas(w, r, IdeaRequest{
+		Domain:      r.FormValue("domain"),
+		Description: description,
+	})
+}